@@ -0,0 +1,534 @@
+package soba
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Any creates a Field with given key and an arbitrary value.
+//
+// It uses the typed constructor of the value if it's a known type, such as Int, Strings or Durations.
+// Then, it relies on Redactable, error, ObjectMarshaler, ArrayMarshaler or fmt.Stringer if the value implements
+// one of them.
+// Finally, it falls back to reflection for struct, map, slice and pointer, which is way more expensive.
+//
+// When using reflection, a value nested deeper than MaxReflectDepth or a cyclic reference will be encoded
+// as null. Also, for struct, only exported fields are encoded and the "json" tag can be used to rename or
+// skip a field.
+// nolint: gocyclo
+func Any(key string, value interface{}) Field {
+	if isNilValue(value) {
+		return Null(key)
+	}
+
+	switch value := value.(type) {
+	case Redactable:
+		return String(key, value.Redact())
+	case error:
+		return NamedError(key, value)
+	case ObjectMarshaler:
+		return Object(key, value)
+	case ArrayMarshaler:
+		return Array(key, value)
+	case bool:
+		return Bool(key, value)
+	case int:
+		return Int(key, value)
+	case int8:
+		return Int8(key, value)
+	case int16:
+		return Int16(key, value)
+	case int32:
+		return Int32(key, value)
+	case int64:
+		return Int64(key, value)
+	case uint:
+		return Uint(key, value)
+	case uint8:
+		return Uint8(key, value)
+	case uint16:
+		return Uint16(key, value)
+	case uint32:
+		return Uint32(key, value)
+	case uint64:
+		return Uint64(key, value)
+	case float32:
+		return Float32(key, value)
+	case float64:
+		return Float64(key, value)
+	case string:
+		return String(key, value)
+	case time.Time:
+		return Time(key, value)
+	case time.Duration:
+		return Duration(key, value)
+	case []byte:
+		return Binary(key, value)
+	case []bool:
+		return Bools(key, value)
+	case []int:
+		return Ints(key, value)
+	case []int8:
+		return Int8s(key, value)
+	case []int16:
+		return Int16s(key, value)
+	case []int32:
+		return Int32s(key, value)
+	case []int64:
+		return Int64s(key, value)
+	case []uint:
+		return Uints(key, value)
+	case []uint16:
+		return Uint16s(key, value)
+	case []uint32:
+		return Uint32s(key, value)
+	case []uint64:
+		return Uint64s(key, value)
+	case []float32:
+		return Float32s(key, value)
+	case []float64:
+		return Float64s(key, value)
+	case []string:
+		return Strings(key, value)
+	case []time.Time:
+		return Times(key, value)
+	case []time.Duration:
+		return Durations(key, value)
+	case []error:
+		return Errors(key, value)
+	case []fmt.Stringer:
+		return Stringers(key, value)
+	case []ObjectMarshaler:
+		return Objects(key, value)
+	case fmt.Stringer:
+		return Stringer(key, value)
+	default:
+		return Reflect(key, value)
+	}
+}
+
+// Reflect creates a Field with given key and an arbitrary value, using reflection to encode it.
+// Please consider using Any instead, which uses faster alternatives whenever possible.
+func Reflect(key string, value interface{}) Field {
+	return newField(key, func(encoder Encoder, key string) {
+		walker := &reflectWalker{}
+		walker.add(encoder, key, reflect.ValueOf(value))
+	})
+}
+
+// MaxReflectDepth defines the maximum depth of nested values encoded using reflection.
+const MaxReflectDepth = 16
+
+// reflectKind defines how a type should be encoded by the reflection walker.
+type reflectKind uint8
+
+const (
+	reflectNative = reflectKind(iota)
+	reflectRedactable
+	reflectObjectMarshaler
+	reflectArrayMarshaler
+	reflectError
+	reflectTime
+	reflectDuration
+	reflectBinary
+	reflectStringer
+)
+
+var (
+	redactableType      = reflect.TypeOf((*Redactable)(nil)).Elem()
+	objectMarshalerType = reflect.TypeOf((*ObjectMarshaler)(nil)).Elem()
+	arrayMarshalerType  = reflect.TypeOf((*ArrayMarshaler)(nil)).Elem()
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// reflectType contains the cached informations of a type for the reflection walker.
+type reflectType struct {
+	kind   reflectKind
+	fields []reflectField
+}
+
+// reflectField describes an encoded field of a struct.
+type reflectField struct {
+	name  string
+	index int
+}
+
+// reflectTypes is a cache of reflectType identified by their reflect.Type.
+var reflectTypes = sync.Map{}
+
+// getReflectType returns the cached informations of given type.
+func getReflectType(kind reflect.Type) *reflectType {
+	val, ok := reflectTypes.Load(kind)
+	if ok {
+		return val.(*reflectType)
+	}
+
+	info := newReflectType(kind)
+	val, _ = reflectTypes.LoadOrStore(kind, info)
+	return val.(*reflectType)
+}
+
+// newReflectType analyzes given type to establish how it should be encoded.
+func newReflectType(kind reflect.Type) *reflectType {
+	info := &reflectType{}
+
+	switch {
+	case kind.Implements(redactableType):
+		info.kind = reflectRedactable
+	case kind.Implements(errorType):
+		info.kind = reflectError
+	case kind.Implements(objectMarshalerType):
+		info.kind = reflectObjectMarshaler
+	case kind.Implements(arrayMarshalerType):
+		info.kind = reflectArrayMarshaler
+	case kind == timeType:
+		info.kind = reflectTime
+	case kind == durationType:
+		info.kind = reflectDuration
+	case kind.Kind() == reflect.Slice && kind.Elem().Kind() == reflect.Uint8:
+		info.kind = reflectBinary
+	case kind.Implements(stringerType):
+		info.kind = reflectStringer
+	}
+
+	if info.kind == reflectNative && kind.Kind() == reflect.Struct {
+		info.fields = getReflectFields(kind)
+	}
+
+	return info
+}
+
+// getReflectFields returns the exported fields of given struct type.
+func getReflectFields(kind reflect.Type) []reflectField {
+	fields := make([]reflectField, 0, kind.NumField())
+
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		tag = strings.Split(tag, ",")[0]
+		if tag != "" {
+			name = tag
+		}
+
+		fields = append(fields, reflectField{
+			name:  name,
+			index: i,
+		})
+	}
+
+	return fields
+}
+
+// reflectWalker encodes an arbitrary value using reflection.
+// It keeps track of the current depth and the visited references, so it must not be shared between encoders.
+type reflectWalker struct {
+	depth   int
+	visited map[uintptr]struct{}
+}
+
+// enter marks given reference as visited before encoding a nested value.
+// It returns false if the reference is already visited, or if the maximum depth is reached.
+func (walker *reflectWalker) enter(ref uintptr) bool {
+	if walker.depth >= MaxReflectDepth {
+		return false
+	}
+
+	if ref != 0 {
+		if walker.visited == nil {
+			walker.visited = map[uintptr]struct{}{}
+		}
+
+		_, ok := walker.visited[ref]
+		if ok {
+			return false
+		}
+
+		walker.visited[ref] = struct{}{}
+	}
+
+	walker.depth++
+	return true
+}
+
+// leave removes given reference from the visited ones once the nested value is encoded.
+func (walker *reflectWalker) leave(ref uintptr) {
+	if ref != 0 {
+		delete(walker.visited, ref)
+	}
+	walker.depth--
+}
+
+// add encodes given value with given key.
+// nolint: gocyclo
+func (walker *reflectWalker) add(encoder ObjectEncoder, key string, value reflect.Value) {
+	value, ref, ok := walker.indirect(value)
+	if !ok {
+		encoder.AddNull(key)
+		return
+	}
+
+	info := getReflectType(value.Type())
+	if info.kind != reflectNative {
+		walker.addInterface(encoder, key, value, info.kind)
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		encoder.AddBool(key, value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoder.AddInt64(key, value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encoder.AddUint64(key, value.Uint())
+	case reflect.Float32:
+		encoder.AddFloat32(key, float32(value.Float()))
+	case reflect.Float64:
+		encoder.AddFloat64(key, value.Float())
+	case reflect.String:
+		encoder.AddString(key, value.String())
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if !walker.enter(ref) {
+			encoder.AddNull(key)
+			return
+		}
+		if value.Kind() == reflect.Struct || value.Kind() == reflect.Map {
+			encoder.AddObject(key, reflectObject{walker: walker, value: value})
+		} else {
+			encoder.AddArray(key, reflectArray{walker: walker, value: value})
+		}
+		walker.leave(ref)
+	default:
+		encoder.AddString(key, value.Type().String())
+	}
+}
+
+// append encodes given value in an array.
+// nolint: gocyclo
+func (walker *reflectWalker) append(encoder ArrayEncoder, value reflect.Value) {
+	value, ref, ok := walker.indirect(value)
+	if !ok {
+		encoder.AppendNull()
+		return
+	}
+
+	info := getReflectType(value.Type())
+	if info.kind != reflectNative {
+		walker.appendInterface(encoder, value, info.kind)
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		encoder.AppendBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoder.AppendInt64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encoder.AppendUint64(value.Uint())
+	case reflect.Float32:
+		encoder.AppendFloat32(float32(value.Float()))
+	case reflect.Float64:
+		encoder.AppendFloat64(value.Float())
+	case reflect.String:
+		encoder.AppendString(value.String())
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if !walker.enter(ref) {
+			encoder.AppendNull()
+			return
+		}
+		if value.Kind() == reflect.Struct || value.Kind() == reflect.Map {
+			encoder.AppendObject(reflectObject{walker: walker, value: value})
+		} else {
+			encoder.AppendArray(reflectArray{walker: walker, value: value})
+		}
+		walker.leave(ref)
+	default:
+		encoder.AppendString(value.Type().String())
+	}
+}
+
+// addInterface encodes given value with given key, using the interface it implements.
+func (walker *reflectWalker) addInterface(encoder ObjectEncoder, key string, value reflect.Value, kind reflectKind) {
+	if !value.CanInterface() {
+		encoder.AddNull(key)
+		return
+	}
+
+	switch kind {
+	case reflectRedactable:
+		encoder.AddString(key, value.Interface().(Redactable).Redact())
+	case reflectObjectMarshaler:
+		encoder.AddObject(key, value.Interface().(ObjectMarshaler))
+	case reflectArrayMarshaler:
+		encoder.AddArray(key, value.Interface().(ArrayMarshaler))
+	case reflectError:
+		encoder.AddObject(key, errorObject{err: value.Interface().(error)})
+	case reflectTime:
+		encoder.AddTime(key, value.Interface().(time.Time))
+	case reflectDuration:
+		encoder.AddDuration(key, time.Duration(value.Int()))
+	case reflectBinary:
+		encoder.AddBinary(key, value.Bytes())
+	case reflectStringer:
+		encoder.AddStringer(key, value.Interface().(fmt.Stringer))
+	}
+}
+
+// appendInterface encodes given value in an array, using the interface it implements.
+func (walker *reflectWalker) appendInterface(encoder ArrayEncoder, value reflect.Value, kind reflectKind) {
+	if !value.CanInterface() {
+		encoder.AppendNull()
+		return
+	}
+
+	switch kind {
+	case reflectRedactable:
+		encoder.AppendString(value.Interface().(Redactable).Redact())
+	case reflectObjectMarshaler:
+		encoder.AppendObject(value.Interface().(ObjectMarshaler))
+	case reflectArrayMarshaler:
+		encoder.AppendArray(value.Interface().(ArrayMarshaler))
+	case reflectError:
+		encoder.AppendObject(errorObject{err: value.Interface().(error)})
+	case reflectTime:
+		encoder.AppendTime(value.Interface().(time.Time))
+	case reflectDuration:
+		encoder.AppendDuration(time.Duration(value.Int()))
+	case reflectBinary:
+		encoder.AppendBinary(value.Bytes())
+	case reflectStringer:
+		encoder.AppendString(value.Interface().(fmt.Stringer).String())
+	}
+}
+
+// indirect dereferences given value until it's neither a pointer nor an interface.
+// It returns the reference of the last dereferenced pointer (or the map itself) to detect cyclic references,
+// and false if the value is nil.
+func (walker *reflectWalker) indirect(value reflect.Value) (reflect.Value, uintptr, bool) {
+	ref := uintptr(0)
+	for {
+		if !value.IsValid() {
+			return value, ref, false
+		}
+
+		switch value.Kind() {
+		case reflect.Interface:
+			if value.IsNil() {
+				return value, ref, false
+			}
+			value = value.Elem()
+
+		case reflect.Ptr:
+			if value.IsNil() {
+				return value, ref, false
+			}
+			// A pointer that implements an interface is encoded using this interface.
+			if getReflectType(value.Type()).kind != reflectNative {
+				return value, ref, true
+			}
+			ref = value.Pointer()
+			value = value.Elem()
+
+		case reflect.Map:
+			if value.IsNil() {
+				return value, ref, false
+			}
+			return value, value.Pointer(), true
+
+		default:
+			return value, ref, true
+		}
+	}
+}
+
+// reflectObject is an ObjectMarshaler for struct and map encoded using reflection.
+type reflectObject struct {
+	walker *reflectWalker
+	value  reflect.Value
+}
+
+// Encode registers object properties in the logging context.
+func (object reflectObject) Encode(encoder ObjectEncoder) {
+	walker := object.walker
+	value := object.value
+
+	if value.Kind() == reflect.Struct {
+		for _, field := range getReflectType(value.Type()).fields {
+			walker.add(encoder, field.name, value.Field(field.index))
+		}
+		return
+	}
+
+	keys := value.MapKeys()
+	names := make([]string, len(keys))
+	indexes := make(map[string]int, len(keys))
+	for i := range keys {
+		names[i] = getReflectMapKey(keys[i])
+		indexes[names[i]] = i
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		walker.add(encoder, name, value.MapIndex(keys[indexes[name]]))
+	}
+}
+
+// reflectArray is an ArrayMarshaler for slice and array encoded using reflection.
+type reflectArray struct {
+	walker *reflectWalker
+	value  reflect.Value
+}
+
+// Encode registers array elements in the logging context.
+func (array reflectArray) Encode(encoder ArrayEncoder) {
+	walker := array.walker
+	value := array.value
+
+	for i := 0; i < value.Len(); i++ {
+		walker.append(encoder, value.Index(i))
+	}
+}
+
+// getReflectMapKey converts given map key to a string.
+func getReflectMapKey(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10)
+	default:
+		if key.CanInterface() {
+			return fmt.Sprint(key.Interface())
+		}
+		return key.Type().String()
+	}
+}
+
+// isNilValue returns if given value is nil or a nil pointer.
+func isNilValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,250 @@
+package soba_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/novln/soba"
+)
+
+// TestNode is a struct with a reference on itself for test.
+type TestNode struct {
+	Name string
+	Next *TestNode
+}
+
+// TestUser is a struct with tags, unexported and nested fields for test.
+type TestUser struct {
+	ID        int64             `json:"id"`
+	Email     string            `json:"email,omitempty"`
+	Password  string            `json:"-"`
+	Roles     []string          `json:"roles"`
+	Address   *TestAddress      `json:"address"`
+	Labels    map[string]string `json:"labels"`
+	CreatedAt time.Time         `json:"created_at"`
+	Timeout   time.Duration
+	Object    TestObject
+	secret    string
+}
+
+// TestAddress is a nested struct for test.
+type TestAddress struct {
+	City string `json:"city"`
+}
+
+// Benchmark creation and encoding of an Any field using reflection.
+func BenchmarkField_AnyReflect(b *testing.B) {
+	user := TestUser{
+		ID:      42,
+		Email:   "john@example.com",
+		Roles:   []string{"admin", "user"},
+		Address: &TestAddress{City: "Paris"},
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = DebugField(soba.Any("user", user))
+	}
+}
+
+// Test field with values of known types.
+func TestField_AnyKnownTypes(t *testing.T) {
+	now := time.Date(2019, 4, 20, 9, 53, 13, 0, time.UTC)
+
+	scenarios := []struct {
+		value    interface{}
+		expected string
+	}{
+		{
+			// Scenario #1
+			value:    nil,
+			expected: `"key":null`,
+		},
+		{
+			// Scenario #2
+			value:    42,
+			expected: `"key":42`,
+		},
+		{
+			// Scenario #3
+			value:    uint8(8),
+			expected: `"key":8`,
+		},
+		{
+			// Scenario #4
+			value:    3.14,
+			expected: `"key":3.14`,
+		},
+		{
+			// Scenario #5
+			value:    "foobar",
+			expected: `"key":"foobar"`,
+		},
+		{
+			// Scenario #6
+			value:    true,
+			expected: `"key":true`,
+		},
+		{
+			// Scenario #7
+			value:    now,
+			expected: `"key":"2019-04-20T09:53:13Z"`,
+		},
+		{
+			// Scenario #8
+			value:    3 * time.Second,
+			expected: `"key":"3s"`,
+		},
+		{
+			// Scenario #9
+			value:    []byte("Hello world"),
+			expected: `"key":"SGVsbG8gd29ybGQ="`,
+		},
+		{
+			// Scenario #10
+			value:    []int{1, 2, 3},
+			expected: `"key":[1,2,3]`,
+		},
+		{
+			// Scenario #11
+			value:    []time.Duration{time.Second, time.Minute},
+			expected: `"key":["1s","1m0s"]`,
+		},
+		{
+			// Scenario #12
+			value:    []string{"foo", "bar"},
+			expected: `"key":["foo","bar"]`,
+		},
+		{
+			// Scenario #13
+			value:    TestObject{Key: "alpha", Value: 7},
+			expected: `"key":{"key":"alpha","value":7}`,
+		},
+		{
+			// Scenario #14
+			value:    TestArray{Objects: []TestObject{{Key: "beta", Value: 8}}},
+			expected: `"key":[{"key":"beta","value":8}]`,
+		},
+		{
+			// Scenario #15
+			value:    errors.New("foobar"),
+			expected: `"key":{"message":"foobar","type":"*errors.errorString"}`,
+		},
+		{
+			// Scenario #16
+			value:    net.IPv4(10, 0, 7, 23),
+			expected: `"key":"10.0.7.23"`,
+		},
+		{
+			// Scenario #17
+			value:    (*TestObject)(nil),
+			expected: `"key":null`,
+		},
+		{
+			// Scenario #18
+			value:    TestError{Code: 503},
+			expected: `"key":{"message":"unexpected status code: 503","type":"soba_test.TestError","code":503}`,
+		},
+		{
+			// Scenario #19
+			value: struct {
+				Err TestError `json:"err"`
+			}{Err: TestError{Code: 404}},
+			expected: `"key":{"err":{"message":"unexpected status code: 404","type":"soba_test.TestError","code":404}}`,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		message := fmt.Sprintf("scenario #%d", (i + 1))
+		value := DebugField(soba.Any("key", scenario.value))
+		if value != scenario.expected {
+			t.Fatalf("Unexpected value for %s: '%s' should be '%s'", message, value, scenario.expected)
+		}
+	}
+}
+
+// Test field with a struct using reflection.
+func TestField_AnyStruct(t *testing.T) {
+	user := &TestUser{
+		ID:        42,
+		Email:     "john@example.com",
+		Password:  "123456",
+		Roles:     []string{"admin", "user"},
+		Address:   &TestAddress{City: "Paris"},
+		Labels:    map[string]string{"team": "core", "env": "prod"},
+		CreatedAt: time.Date(2019, 4, 20, 9, 53, 13, 0, time.UTC),
+		Timeout:   5 * time.Second,
+		Object:    TestObject{Key: "gamma", Value: 9},
+		secret:    "hidden",
+	}
+
+	field := soba.Any("user", user)
+	expected := fmt.Sprint(
+		`"user":{"id":42,"email":"john@example.com","roles":["admin","user"],`,
+		`"address":{"city":"Paris"},"labels":{"env":"prod","team":"core"},`,
+		`"created_at":"2019-04-20T09:53:13Z","Timeout":"5s","Object":{"key":"gamma","value":9}}`,
+	)
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with a map using reflection.
+func TestField_AnyMap(t *testing.T) {
+	field := soba.Any("key", map[int]interface{}{
+		3: nil,
+		1: []interface{}{"foo", 2, false},
+		2: &TestAddress{City: "Lyon"},
+	})
+	expected := `"key":{"1":["foo",2,false],"2":{"city":"Lyon"},"3":null}`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with a cyclic reference using reflection.
+func TestField_AnyCycle(t *testing.T) {
+	first := &TestNode{Name: "first"}
+	second := &TestNode{Name: "second", Next: first}
+	first.Next = second
+
+	field := soba.Any("node", first)
+	expected := `"node":{"Name":"first","Next":{"Name":"second","Next":null}}`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with a value nested deeper than the maximum depth using reflection.
+func TestField_AnyDepth(t *testing.T) {
+	var value interface{} = "leaf"
+	for i := 0; i < soba.MaxReflectDepth+1; i++ {
+		value = []interface{}{value}
+	}
+
+	result := DebugField(soba.Any("key", value))
+
+	expected := "null"
+	for i := 0; i < soba.MaxReflectDepth; i++ {
+		expected = fmt.Sprint("[", expected, "]")
+	}
+	expected = fmt.Sprint(`"key":`, expected)
+
+	if expected != result {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", result, expected)
+	}
+}
@@ -1,6 +1,7 @@
 package soba
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -10,8 +11,6 @@ import (
 	"sync"
 
 	"github.com/pkg/errors"
-
-	"github.com/novln/soba/encoder/json"
 )
 
 const (
@@ -32,48 +31,83 @@ type Appender interface {
 	Close() error
 }
 
+// A Syncer is an Appender that buffers its log entries, and can flush them to a durable storage on demand.
+// It's an optional interface.
+type Syncer interface {
+	// Sync flushes buffered log entries.
+	Sync() error
+}
+
+// A Shutdowner is an Appender that writes its log entries asynchronously, and that drains them before
+// releasing its resources. It's an optional interface: if implemented, it's used instead of Close when the handler
+// is shut down.
+type Shutdowner interface {
+	// Shutdown drains pending log entries and recycles underlying resources of appender, unless given context
+	// is done before.
+	Shutdown(ctx context.Context) error
+}
+
 // IsAppenderNameValid verify that a Appender name has a valid format.
 var IsAppenderNameValid = regexp.MustCompile(`^[a-z]+[a-z._0-9-]+[a-z0-9]+$`).MatchString
 
 // NewAppender creates a new Appender from given configuration.
-// To register a custom appender, please use soba.RegisterAppenders() function.
+// To register a custom appender type, please use soba.RegisterAppenderType() function.
 func NewAppender(name string, conf ConfigAppender) (Appender, error) {
-	err := validateAppenderConfig(name, conf)
+	return defaultRegistry.NewAppender(name, conf)
+}
+
+// newConsoleAppenderFromConfig is the factory of ConsoleAppenderType.
+func newConsoleAppenderFromConfig(name string, conf ConfigAppender) (Appender, error) {
+	schema, err := NewSchema(conf.Schema)
 	if err != nil {
-		return nil, errors.Wrapf(err, "cannot create appender for %s", name)
+		return nil, errors.Wrapf(err, "cannot create schema for appender %s", name)
+	}
+
+	appender := NewConsoleAppender(name, os.Stdout)
+	if conf.Encoder != "" {
+		appender.encoder = conf.Encoder
 	}
+	appender.schema = schema
 
-	switch conf.Type {
-	case ConsoleAppenderType:
-		appender := NewConsoleAppender(name, os.Stdout)
-		return appender, nil
+	return appender, nil
+}
 
-	case FileAppenderType:
-		appender, err := NewFileAppender(name, conf.Path, conf.Backup, conf.MaxBytes)
-		if err != nil {
-			return nil, errors.Wrapf(err, "cannot create file appender for %s", name)
-		}
+// newFileAppenderFromConfig is the factory of FileAppenderType.
+func newFileAppenderFromConfig(name string, conf ConfigAppender) (Appender, error) {
+	schema, err := NewSchema(conf.Schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create schema for appender %s", name)
+	}
 
-		return appender, nil
+	appender, err := NewFileAppender(name, conf.Path, conf.Backup, conf.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
 
-	default:
-		// Should be handled by validateAppenderConfig function.
-		return nil, errors.Errorf("unknown appender type for %s: %s", name, conf.Type)
+	if conf.Encoder != "" {
+		appender.encoder = conf.Encoder
 	}
+	appender.schema = schema
+
+	return appender, nil
 }
 
 // ConsoleAppender is an appender that uses stdout to write log entry.
 type ConsoleAppender struct {
-	mutex sync.Mutex
-	name  string
-	out   io.Writer
+	mutex   sync.Mutex
+	name    string
+	out     io.Writer
+	encoder string
+	schema  *Schema
 }
 
 // NewConsoleAppender creates a new ConsoleAppender instance.
 func NewConsoleAppender(name string, out io.Writer) *ConsoleAppender {
 	return &ConsoleAppender{
-		name: name,
-		out:  out,
+		name:    name,
+		out:     out,
+		encoder: JSONEncoderType,
+		schema:  DefaultSchema,
 	}
 }
 
@@ -89,10 +123,7 @@ func (appender *ConsoleAppender) Close() error {
 
 // Write receives a log entry.
 func (appender *ConsoleAppender) Write(entry *Entry) {
-	encoder := json.NewEncoder()
-	defer encoder.Close()
-
-	buffer := WriteEntry(entry, encoder)
+	buffer := entry.EncodeWithSchema(appender.encoder, appender.schema)
 
 	appender.mutex.Lock()
 	defer appender.mutex.Unlock()
@@ -112,6 +143,9 @@ type FileAppender struct {
 	size     int64
 	backup   bool
 	maxBytes int64
+	encoder  string
+	schema   *Schema
+	closed   bool
 }
 
 // NewFileAppender creates a new FileAppender instance.
@@ -121,6 +155,8 @@ func NewFileAppender(name string, path string, backup bool, maxBytes int64) (*Fi
 		path:     path,
 		backup:   backup,
 		maxBytes: maxBytes,
+		encoder:  JSONEncoderType,
+		schema:   DefaultSchema,
 	}
 
 	err := appender.openNew()
@@ -137,23 +173,43 @@ func (appender *FileAppender) Name() string {
 }
 
 // Close recycles underlying resources of appender.
+// Once closed, the appender discards every log entry.
 func (appender *FileAppender) Close() error {
 	appender.mutex.Lock()
 	defer appender.mutex.Unlock()
 
+	appender.closed = true
 	return appender.close()
 }
 
+// Sync commits the current content of the file to stable storage.
+func (appender *FileAppender) Sync() error {
+	appender.mutex.Lock()
+	defer appender.mutex.Unlock()
+
+	if appender.file == nil {
+		return nil
+	}
+
+	err := appender.file.Sync()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
 // Write receives a log entry and writes it on a file.
 func (appender *FileAppender) Write(entry *Entry) {
-	encoder := json.NewEncoder()
-	defer encoder.Close()
-
-	buffer := WriteEntry(entry, encoder)
+	buffer := entry.EncodeWithSchema(appender.encoder, appender.schema)
 
 	appender.mutex.Lock()
 	defer appender.mutex.Unlock()
 
+	if appender.closed {
+		return
+	}
+
 	err := appender.rotate(len(buffer))
 	if err != nil {
 		onAppenderWriteError(err)
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/novln/soba"
 	"github.com/novln/soba/encoder/json"
 )
@@ -79,6 +81,45 @@ func CloseAppender(t *testing.T, appender soba.Appender) {
 	}
 }
 
+// TestAppenderType defines the appender type used to configure given appenders with NewTestRegistry.
+const TestAppenderType = "test"
+
+// NewTestRegistry creates a new registry whose "test" appender type returns given appenders, and declares them in
+// given configuration.
+func NewTestRegistry(t *testing.T, conf *soba.Config, appenders ...soba.Appender) *soba.Registry {
+	t.Helper()
+
+	instances := map[string]soba.Appender{}
+	for _, appender := range appenders {
+		instances[appender.Name()] = appender
+	}
+
+	factory := func(name string, _ soba.ConfigAppender) (soba.Appender, error) {
+		appender, ok := instances[name]
+		if !ok {
+			return nil, errors.Errorf("unknown test appender: %s", name)
+		}
+		return appender, nil
+	}
+
+	registry := soba.NewRegistry()
+	err := registry.RegisterAppenderType(TestAppenderType, factory)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if conf.Appenders == nil {
+		conf.Appenders = map[string]soba.ConfigAppender{}
+	}
+	for name := range instances {
+		conf.Appenders[name] = soba.ConfigAppender{
+			Type: TestAppenderType,
+		}
+	}
+
+	return registry
+}
+
 // Test appender name format.
 func TestAppender_IsNameValid(t *testing.T) {
 
@@ -296,6 +337,42 @@ func TestAppender_InvalidNew(t *testing.T) {
 	}
 }
 
+// Test appender constructor with an invalid encoder.
+func TestAppender_InvalidEncoder(t *testing.T) {
+	name := "invalid"
+	appender, err := soba.NewAppender(name, soba.ConfigAppender{
+		Type:    soba.ConsoleAppenderType,
+		Encoder: "xml",
+	})
+	if err == nil {
+		CloseAppender(t, appender)
+		t.Fatalf(`An error was expected for appender "%s" (invalid encoder)`, name)
+	}
+}
+
+// Benchmark writing an entry with one, three and five appenders using the same encoder.
+func BenchmarkAppender_SharedEncoder(b *testing.B) {
+	for _, size := range []int{1, 3, 5} {
+		b.Run(fmt.Sprintf("appenders-%d", size), func(b *testing.B) {
+			appenders := []soba.Appender{}
+			for i := 0; i < size; i++ {
+				appenders = append(appenders, soba.NewConsoleAppender(fmt.Sprint("console-", i), ioutil.Discard))
+			}
+
+			logger := soba.NewLogger("foobar", soba.InfoLevel, appenders)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info("Request received",
+					soba.String("method", "GET"), soba.String("path", "/users/01CV5FN4JF1STZMYDJWMGQR68W"),
+					soba.Int("status", 200), soba.Duration("elapsed", 3*time.Millisecond),
+				)
+			}
+		})
+	}
+}
+
 // Test console appender constructor.
 func TestAppender_ConsoleNew(t *testing.T) {
 	name := "console1"
@@ -693,3 +770,51 @@ func TestAppender_FileWrite(t *testing.T) {
 	// Cleanup
 	deleteFiles()
 }
+
+// Test file appender synchronization, and writing after it has been closed.
+func TestAppender_FileSync(t *testing.T) {
+	path := "testdata/logs/sync-file.log"
+
+	appender, err := soba.NewFileAppender("file", path, false, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	defer func() {
+		err = os.Remove(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}()
+
+	entry := soba.NewEntry("foobar", soba.InfoLevel, "Checkpoint reached")
+	defer entry.Flush()
+
+	appender.Write(entry)
+
+	var syncer soba.Syncer = appender
+	err = syncer.Sync()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	err = appender.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	err = appender.Sync()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	// Once closed, the appender should discard the entry rather than writing it on a closed file.
+	appender.Write(entry)
+
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if strings.Count(string(buffer), "\n") != 1 {
+		t.Fatalf("Unexpected file content: %s", string(buffer))
+	}
+}
@@ -22,6 +22,7 @@ var DefaultFilterOptions = &FilterOptions{
 		Include: []string{},
 		Exclude: []string{},
 	},
+	Schema: soba.DefaultSchema,
 }
 
 // FilterOptions is the configuration for the filter handler.
@@ -35,6 +36,9 @@ type FilterOptions struct {
 	// Messages are the filters used for the entry message.
 	// This filter type will checks that the message contains (or not) the given value.
 	Messages FilterAction
+	// Schema defines the keys used to read the logger name, the entry priority and the entry message.
+	// By default, it's soba.DefaultSchema.
+	Schema *soba.Schema
 }
 
 // HasFilters returns true is this options has filters.
@@ -79,9 +83,34 @@ func NewFilterHandler(opts *FilterOptions) *FilterHandler {
 		filters: *opts,
 	}
 
+	if handler.filters.Schema == nil {
+		handler.filters.Schema = soba.DefaultSchema
+	}
+
+	handler.filters.Levels = FilterAction{
+		Include: getFilterLevels(handler.filters.Schema, opts.Levels.Include),
+		Exclude: getFilterLevels(handler.filters.Schema, opts.Levels.Exclude),
+	}
+
 	return handler
 }
 
+// getFilterLevels converts given level names to the values written by given schema, such as "WARNING" or "4".
+// An unknown level name is used as it is.
+func getFilterLevels(schema *soba.Schema, levels []string) []string {
+	list := make([]string, 0, len(levels))
+
+	for _, value := range levels {
+		level, ok := soba.ParseLevel(value)
+		if ok {
+			value = schema.FormatLevel(level)
+		}
+		list = append(list, value)
+	}
+
+	return list
+}
+
 // Filter inspect the input json to establish if it's required using a list of custom rules.
 // This function return the json if it's desired, nil otherwise.
 func (handler *FilterHandler) Filter(json []byte) []byte {
@@ -99,17 +128,19 @@ func (handler *FilterHandler) Filter(json []byte) []byte {
 		return json
 	}
 
-	level := v.GetStringBytes(soba.LevelKey)
+	schema := handler.filters.Schema
+
+	level := getFilterLevel(v.Get(schema.LevelKey))
 	if filter(bytes.Equal, handler.filters.Levels, level) {
 		return nil
 	}
 
-	logger := v.GetStringBytes(soba.LoggerKey)
+	logger := v.GetStringBytes(schema.LoggerKey)
 	if filter(bytes.HasPrefix, handler.filters.Loggers, logger) {
 		return nil
 	}
 
-	message := v.GetStringBytes(soba.MessageKey)
+	message := v.GetStringBytes(schema.MessageKey)
 	if filter(bytes.Contains, handler.filters.Messages, message) {
 		return nil
 	}
@@ -117,6 +148,23 @@ func (handler *FilterHandler) Filter(json []byte) []byte {
 	return json
 }
 
+// getFilterLevel returns the entry priority from given json value, which could be a string or a syslog severity
+// number.
+func getFilterLevel(value *fjson.Value) []byte {
+	if value == nil {
+		return nil
+	}
+
+	switch value.Type() {
+	case fjson.TypeString:
+		return value.GetStringBytes()
+	case fjson.TypeNumber:
+		return value.MarshalTo(nil)
+	default:
+		return nil
+	}
+}
+
 func filter(operation func([]byte, []byte) bool, filters FilterAction, value []byte) bool {
 
 	// If there is no filter, no need to excute the exclusion/inclusion.
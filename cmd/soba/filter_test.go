@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"testing"
+
+	"github.com/novln/soba"
 )
 
 func TestFilter_Validator(t *testing.T) {
@@ -485,3 +487,80 @@ func testFilter(t *testing.T, input string, filtered bool, filter *FilterOptions
 		}
 	}
 }
+
+func TestFilter_Schema(t *testing.T) {
+	testCases := []struct {
+		input    string
+		opts     *FilterOptions
+		filtered bool
+	}{
+		{
+			input: fmt.Sprint(
+				`{"log.logger":"repositories.users","log.level":"info",`,
+				`"message":"User created","id":"01CV5FN4JF1STZMYDJWMGQR68W"}`,
+			),
+			opts: &FilterOptions{
+				Loggers: FilterAction{
+					Exclude: []string{},
+					Include: []string{
+						"repositories",
+					},
+				},
+				Schema: soba.ECSSchema,
+			},
+			filtered: false,
+		},
+		{
+			input: fmt.Sprint(
+				`{"logger":"repositories.users","severity":"WARNING",`,
+				`"message":"User created","id":"01CV5FN4JF1STZMYDJWMGQR68W"}`,
+			),
+			opts: &FilterOptions{
+				Levels: FilterAction{
+					Exclude: []string{},
+					Include: []string{
+						"warn",
+					},
+				},
+				Schema: soba.GCPSchema,
+			},
+			filtered: false,
+		},
+		{
+			input: fmt.Sprint(
+				`{"_logger":"repositories.users","level":6,`,
+				`"short_message":"User created","_id":"01CV5FN4JF1STZMYDJWMGQR68W"}`,
+			),
+			opts: &FilterOptions{
+				Levels: FilterAction{
+					Exclude: []string{
+						"info",
+					},
+					Include: []string{},
+				},
+				Schema: soba.GELFSchema,
+			},
+			filtered: true,
+		},
+		{
+			input: fmt.Sprint(
+				`{"_logger":"repositories.users","level":6,`,
+				`"short_message":"User created","_id":"01CV5FN4JF1STZMYDJWMGQR68W"}`,
+			),
+			opts: &FilterOptions{
+				Messages: FilterAction{
+					Exclude: []string{},
+					Include: []string{
+						"User",
+					},
+				},
+				Schema: soba.GELFSchema,
+			},
+			filtered: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testFilter(t, testCase.input, testCase.filtered, testCase.opts)
+	}
+}
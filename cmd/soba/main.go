@@ -17,13 +17,22 @@ func main() {
 	colorOpt := getCLIColorOption(app)
 	includeOpts := getCLIIncludeOption(app)
 	excludeOpts := getCLIExcludeOption(app)
+	schemaOpt := getCLISchemaOption(app)
+	schemaKeysOpt := getCLISchemaKeysOption(app)
+	levelFormatOpt := getCLILevelFormatOption(app)
 
 	app.Action = func() {
+		schema, err := parseCLISchemaOption(*schemaOpt, *schemaKeysOpt, *levelFormatOpt)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "soba:", err)
+			mowcli.Exit(2)
+		}
+
 		color := NewPrettyHandler(parseCLIColorOption(*colorOpt))
-		filter := NewFilterHandler(parseCLIFilterOptions(*includeOpts, *excludeOpts))
+		filter := NewFilterHandler(parseCLIFilterOptions(*includeOpts, *excludeOpts, schema))
 
 		pipeline := NewPipeline(os.Stdin, os.Stdout, filter, color)
-		err := pipeline.Run()
+		err = pipeline.Run()
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "soba: cannot read standard input:", err)
 			mowcli.Exit(1)
@@ -61,6 +70,58 @@ func getCLIExcludeOption(app *mowcli.Cli) *[]string {
 	))
 }
 
+func getCLISchemaOption(app *mowcli.Cli) *string {
+	return app.StringOpt("s schema", soba.DefaultSchemaName, fmt.Sprint(
+		`Schema used by the logs: "`, soba.DefaultSchemaName, `", "`, soba.ECSSchemaName, `", "`,
+		soba.GCPSchemaName, `" or "`, soba.GELFSchemaName, `"`,
+	))
+}
+
+func getCLISchemaKeysOption(app *mowcli.Cli) *[]string {
+	return app.StringsOpt("k key", []string{}, fmt.Sprint(
+		`Override a key name of the schema:`, "\n",
+		`- "`, soba.LoggerKey, `:log.logger"`, "\n",
+		`- "`, soba.LevelKey, `:severity"`, "\n",
+		`- "`, soba.MessageKey, `:msg"`, "\n",
+	))
+}
+
+func getCLILevelFormatOption(app *mowcli.Cli) *string {
+	return app.StringOpt("l level-format", "", fmt.Sprint(
+		`Override the level format of the schema: "`, soba.LowerLevelFormat, `", "`,
+		soba.UpperLevelFormat, `" or "`, soba.SyslogLevelFormat, `"`,
+	))
+}
+
+// parseCLISchemaOption returns the schema used by the logs, using the same rules as the schema of an appender:
+// a preset, whose key names and level format could be overridden.
+func parseCLISchemaOption(preset string, keys []string, levelFormat string) (*soba.Schema, error) {
+	conf := soba.ConfigSchema{
+		Preset:      preset,
+		LevelFormat: levelFormat,
+	}
+
+	for _, key := range keys {
+		i := strings.Index(key, ":")
+		if i < 0 || key[i+1:] == "" {
+			return nil, fmt.Errorf("invalid schema key: %s", key)
+		}
+
+		switch key[:i] {
+		case soba.LoggerKey:
+			conf.LoggerKey = key[i+1:]
+		case soba.LevelKey:
+			conf.LevelKey = key[i+1:]
+		case soba.MessageKey:
+			conf.MessageKey = key[i+1:]
+		default:
+			return nil, fmt.Errorf("unknown schema key: %s", key[:i])
+		}
+	}
+
+	return soba.NewSchema(conf)
+}
+
 func parseCLIColorOption(opt string) *ColorOptions {
 	switch opt {
 	case "never", "off", "no", "disable":
@@ -75,8 +136,10 @@ func parseCLIColorOption(opt string) *ColorOptions {
 	}
 }
 
-func parseCLIFilterOptions(include []string, exclude []string) *FilterOptions {
-	opts := &FilterOptions{}
+func parseCLIFilterOptions(include []string, exclude []string, schema *soba.Schema) *FilterOptions {
+	opts := &FilterOptions{
+		Schema: schema,
+	}
 
 	loggerKey := fmt.Sprintf("%s:", soba.LoggerKey)
 	levelKey := fmt.Sprintf("%s:", soba.LevelKey)
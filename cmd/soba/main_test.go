@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+func TestMain_SchemaOption(t *testing.T) {
+	// Same schema as the "custom" appender of testdata/schema.yaml.
+	schema, err := parseCLISchemaOption("", []string{"message:msg"}, "upper")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if schema.MessageKey != "msg" || schema.LevelFormat != soba.UpperLevelFormat || schema.LoggerKey != "logger" {
+		t.Fatalf("Unexpected schema: %+v", schema)
+	}
+
+	input := fmt.Sprint(
+		`{"logger":"repositories.users","time":1555754093,"level":"WARNING",`,
+		`"msg":"User created","id":"01CV5FN4JF1STZMYDJWMGQR68W"}`,
+	)
+
+	testFilter(t, input, false, parseCLIFilterOptions([]string{"level:warn", "message:User"}, []string{}, schema))
+	testFilter(t, input, true, parseCLIFilterOptions([]string{}, []string{"level:warn"}, schema))
+
+	schema, err = parseCLISchemaOption(soba.ECSSchemaName, []string{"logger:service.logger"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if schema.LoggerKey != "service.logger" || schema.LevelKey != soba.ECSSchema.LevelKey {
+		t.Fatalf("Unexpected schema: %+v", schema)
+	}
+
+	testCases := []struct {
+		preset      string
+		keys        []string
+		levelFormat string
+	}{
+		{preset: "foobar"},
+		{keys: []string{"message"}},
+		{keys: []string{"message:"}},
+		{keys: []string{"payload:data"}},
+		{levelFormat: "camel"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := parseCLISchemaOption(testCase.preset, testCase.keys, testCase.levelFormat)
+		if err == nil {
+			t.Fatalf("An error was expected for %+v", testCase)
+		}
+	}
+}
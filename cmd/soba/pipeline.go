@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+
+	"github.com/novln/soba/encoder/cbor"
 )
 
 // Pipeline is components that read from stdin (line by line) and dispatch the workload on
 // three goroutines, streaming the lines: "filter", "color" and "print".
 //
+// If stdin is a stream of CBOR entries, they are converted to json lines before the first step/task.
+//
 // Filter is the first step/task: it preserve valid json on the stream that satisfies a list of custom rules.
 // For example, these custom rules could be to discard a log level, eliminate a log message by its content
 // or retain specific loggers.
@@ -47,12 +51,7 @@ func (pipeline *Pipeline) Run() error {
 	pipeline.startColor()
 	pipeline.startFilter()
 
-	scanner := bufio.NewScanner(pipeline.input)
-	for scanner.Scan() {
-		pipeline.filterChan <- scanner.Bytes()
-	}
-
-	err := scanner.Err()
+	err := pipeline.read()
 
 	pipeline.stopFilter()
 	pipeline.stopColor()
@@ -61,6 +60,51 @@ func (pipeline *Pipeline) Run() error {
 	return err
 }
 
+// read sends every line from the input to the "filter" goroutine.
+func (pipeline *Pipeline) read() error {
+	input := bufio.NewReader(pipeline.input)
+
+	if isCBORStream(input) {
+		reader := cbor.NewReader(input)
+		for {
+			line, err := reader.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			pipeline.filterChan <- line
+		}
+	}
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		pipeline.filterChan <- scanner.Bytes()
+	}
+
+	return scanner.Err()
+}
+
+// isCBORStream returns if the input starts with a CBOR entry, which is a map (major type 5) or a self-described
+// CBOR item (tag 55799), rather than a json line.
+func isCBORStream(input *bufio.Reader) bool {
+	buffer, err := input.Peek(1)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case buffer[0]&0xe0 == 0xa0:
+		return true
+	case buffer[0] == 0xd9:
+		buffer, err = input.Peek(3)
+		return err == nil && buffer[1] == 0xd9 && buffer[2] == 0xf7
+	default:
+		return false
+	}
+}
+
 // startPrint launches "filter" goroutine.
 func (pipeline *Pipeline) startFilter() {
 	pipeline.filterChan = make(chan []byte, 1024)
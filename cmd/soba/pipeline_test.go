@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	libencoder "github.com/novln/soba/encoder"
+	"github.com/novln/soba/encoder/cbor"
 )
 
 func TestPipeline(t *testing.T) {
@@ -64,3 +67,78 @@ func TestPipeline(t *testing.T) {
 		}
 	}
 }
+
+func TestPipeline_CBOR(t *testing.T) {
+	color := NewPrettyHandler(&ColorOptions{
+		EnableColor: false,
+	})
+	filter := NewFilterHandler(&FilterOptions{
+		Levels: FilterAction{
+			Exclude: []string{
+				"debug",
+			},
+		},
+	})
+
+	input := &bytes.Buffer{}
+	output := &bytes.Buffer{}
+
+	for _, level := range []string{"info", "debug", "error"} {
+		encoder := cbor.NewEncoder()
+		input.Write(encoder.Encode(func(encoder libencoder.Encoder) {
+			encoder.AddString("level", level)
+			encoder.AddDuration("elapsed", 1500*time.Millisecond)
+			encoder.AddBinary("payload", []byte("soba"))
+		}))
+		encoder.Close()
+	}
+
+	pipeline := NewPipeline(input, output, filter, color)
+	done := make(chan struct{})
+	logs := make(chan string)
+	fatal := make(chan string)
+
+	go func() {
+		logs <- "Start pipeline go routine"
+		err := pipeline.Run()
+		if err != nil {
+			fatal <- fmt.Sprintf("Unexpected error: %+v", err)
+		}
+		logs <- "Stop pipeline go routine"
+		done <- struct{}{}
+	}()
+
+	for {
+		select {
+		case <-done:
+			eol := "\n"
+			expected := fmt.Sprint(
+				`{`, eol,
+				`  "level": "info",`, eol,
+				`  "elapsed": "1.5s",`, eol,
+				`  "payload": "c29iYQ=="`, eol,
+				`}`, eol,
+				`{`, eol,
+				`  "level": "error",`, eol,
+				`  "elapsed": "1.5s",`, eol,
+				`  "payload": "c29iYQ=="`, eol,
+				`}`, eol,
+			)
+
+			if output.String() != expected {
+				t.Fatalf("Unexpected json output: '%s' should be '%s'", output.String(), expected)
+			}
+
+			return
+
+		case log := <-logs:
+			t.Log(log)
+
+		case msg := <-fatal:
+			t.Fatal(msg)
+
+		case <-time.After(10 * time.Second):
+			t.Fatal("Test has timeout")
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package soba
 
 import (
-	"io/ioutil"
+	"fmt"
 	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 
 	"github.com/pkg/errors"
-	yaml "gopkg.in/yaml.v2"
+	yaml "gopkg.in/yaml.v3"
 )
 
 // DefaultConfigPath defines the default configuration path.
@@ -22,34 +27,93 @@ type Config struct {
 	Appenders map[string]ConfigAppender `yaml:"appenders"`
 	// Loggers is a list of loggers configuration.
 	Loggers map[string]ConfigLogger `yaml:"loggers"`
+	// Keys defines how field keys are normalized.
+	Keys ConfigKeys `yaml:"keys"`
+	// Redact defines the rules used to mask or drop sensitive data.
+	Redact ConfigRedact `yaml:"redact"`
+	// Fields is a list of static fields attached to every logger.
+	Fields map[string]interface{} `yaml:"fields"`
+	// Builtins is a list of built-in fields attached to every logger. Could be "hostname", "pid" or "version".
+	Builtins []string `yaml:"builtins"`
 	// verified defines if configuration has been validated.
 	verified bool
+	// positions defines where the keys of the configuration file are declared.
+	positions map[string]position
+	// unknownKeys is the list of keys of the configuration file that don't match any property.
+	unknownKeys []*ConfigIssue
+	// strict defines if an unknown key of the configuration file is an error, rather than a warning.
+	strict bool
+	// warnings is the list of issues found by the last validation, that don't prevent the configuration to be used.
+	warnings []*ConfigIssue
+}
+
+// Warnings returns the issues found by the last validation that don't prevent the configuration to be used, such
+// as an appender referenced by no logger, or an unknown key of the configuration file without strict mode.
+func (conf *Config) Warnings() []*ConfigIssue {
+	return conf.warnings
 }
 
 // IsAppenderExists verifies if appender identified by given name exists.
 func (conf *Config) IsAppenderExists(name string) bool {
 	_, ok := conf.Appenders[name]
-	if ok {
-		return true
-	}
-
-	plMutex.Lock()
-	defer plMutex.Unlock()
-
-	_, ok = plAppenders[name]
 	return ok
 }
 
 // A ConfigLogger describes a logger configuration.
 type ConfigLogger struct {
-	Level     string   `yaml:"level"`
+	// Level defines the maximum level of this logger. If omitted, it's inherited from its nearest configured
+	// ancestor that defines one, or the root logger.
+	Level string `yaml:"level"`
+	// Appenders is a list of appenders directly associated with this logger.
 	Appenders []string `yaml:"appenders"`
-	Additive  bool     `yaml:"additive"`
+	// Additive defines if the appenders of its ancestors are also associated with this logger, until an ancestor
+	// which is not additive. A logger without appenders, which is not disabled, is always additive.
+	// The appenders of a disabled ancestor are still associated with this logger.
+	Additive bool `yaml:"additive"`
+	// Fields is a list of static fields attached to this logger and its descendants.
+	Fields map[string]interface{} `yaml:"fields"`
+}
+
+// A ConfigKeys describes how field keys are normalized.
+type ConfigKeys struct {
+	// Policy defines how field keys are normalized. Could be "preserve", "lower", "snake_case" or "camel_case".
+	// By default, field keys are preserved.
+	Policy string `yaml:"policy"`
+	// Protect enables to prefix a field key that overwrites a reserved key of the appender schema, like "logger",
+	// "message" or one of its static fields.
+	Protect bool `yaml:"protect"`
+}
+
+// A ConfigRedact describes the rules used to mask or drop sensitive data.
+type ConfigRedact struct {
+	// Mask defines the value used to replace a sensitive data. By default, it's "[REDACTED]".
+	Mask string `yaml:"mask"`
+	// Keys is a list of rules that match a field by its key.
+	Keys []ConfigRedactKey `yaml:"keys"`
+	// Values is a list of rules that match a string value.
+	Values []ConfigRedactValue `yaml:"values"`
+}
+
+// A ConfigRedactKey describes a rule that masks or drops a field identified by its key.
+type ConfigRedactKey struct {
+	// Pattern defines a glob pattern for the field key, such as "password".
+	// If the pattern contains a ".", it's matched against the full path of the field, such as "*.token".
+	Pattern string `yaml:"pattern"`
+	// Action defines if the field is masked or dropped. Could be "mask" or "drop". By default, it's masked.
+	Action string `yaml:"action"`
+}
+
+// A ConfigRedactValue describes a rule that masks a part of a string value.
+type ConfigRedactValue struct {
+	// Pattern defines a regular expression for the sensitive part of the value.
+	Pattern string `yaml:"pattern"`
+	// Mask defines the value used to replace the sensitive part. By default, it's the global mask.
+	Mask string `yaml:"mask"`
 }
 
 // A ConfigAppender describes an appender configuration.
 type ConfigAppender struct {
-	// Type defines an appender type. Could be "console" or "file".
+	// Type defines an appender type. Could be "console", "file" or a custom type registered with a factory.
 	Type string `yaml:"type"`
 	// Path defines the file path for a file appender.
 	Path string `yaml:"path"`
@@ -57,6 +121,223 @@ type ConfigAppender struct {
 	MaxBytes int64 `yaml:"max_bytes"`
 	// Backup enables to archive previous log file. It's only activated when MaxBytes is defined.
 	Backup bool `yaml:"backup"`
+	// Encoder defines the encoder used to write entries. Could be "json" or "cbor". By default, it's "json".
+	Encoder string `yaml:"encoder"`
+	// Schema defines the layout of the entries, using either a preset name or a custom configuration.
+	Schema ConfigSchema `yaml:"schema"`
+	// Options defines the configuration of a custom appender type, which is decoded by its factory.
+	Options ConfigOptions `yaml:"options"`
+}
+
+// ConfigOptions describes the free-form configuration of a custom appender type, kept as a raw YAML node.
+// It's decoded into a typed structure by the appender type, using Decode:
+//
+//	type KafkaOptions struct {
+//		Brokers []string `yaml:"brokers"`
+//		Topic   string   `yaml:"topic"`
+//	}
+//
+//	options := KafkaOptions{}
+//	err := conf.Options.Decode(&options)
+type ConfigOptions struct {
+	node *yaml.Node
+	err  error
+}
+
+// NewConfigOptions creates the options of a custom appender type from given values, such as:
+//
+//	soba.NewConfigOptions(map[string]interface{}{"topic": "events"})
+func NewConfigOptions(values map[string]interface{}) ConfigOptions {
+	if len(values) == 0 {
+		return ConfigOptions{}
+	}
+
+	node := &yaml.Node{}
+	err := node.Encode(values)
+	if err != nil {
+		return ConfigOptions{err: errors.Wrap(err, "cannot encode options")}
+	}
+
+	return ConfigOptions{node: node}
+}
+
+// UnmarshalYAML keeps the raw node of the options, so they are decoded by the appender type.
+func (options *ConfigOptions) UnmarshalYAML(value *yaml.Node) error {
+	options.node = value
+	options.err = nil
+	return nil
+}
+
+// IsEmpty returns if no option is defined.
+func (options ConfigOptions) IsEmpty() bool {
+	if options.err != nil {
+		return false
+	}
+	if options.node == nil {
+		return true
+	}
+	return options.node.Kind == yaml.MappingNode && len(options.node.Content) == 0
+}
+
+// Decode decodes the options into given value, which should be a pointer to a structure.
+// Every option must match a field of the structure.
+//
+// Otherwise, a MultiError of ConfigIssue is returned, whose paths are relative to the options, such as
+// "brokers[1]". When it's returned by a validation hook, the issues are then located in the configuration file.
+func (options ConfigOptions) Decode(out interface{}) error {
+	if options.err != nil {
+		return options.err
+	}
+	if options.node == nil {
+		return nil
+	}
+
+	// Every node is numbered with a unique line, so a decoding error is located with its key path.
+	paths := map[int]string{}
+	node := copyOptionsNode(options.node, "", paths)
+	coerceDocumentScalars(node, reflect.TypeOf(out))
+
+	var value interface{}
+	err := node.Decode(&value)
+	if err != nil {
+		return errors.Wrap(err, "cannot decode options")
+	}
+
+	issues := findUnknownKeys(normalizeValue(value), reflect.TypeOf(out), "")
+	for _, issue := range issues {
+		issue.Message = "cannot decode options: " + issue.Message
+	}
+
+	err = node.Decode(out)
+	if err != nil {
+		thr, ok := err.(*yaml.TypeError)
+		if !ok {
+			return errors.Wrap(err, "cannot decode options")
+		}
+
+		for _, message := range thr.Errors {
+			issue := &ConfigIssue{
+				Message: "cannot decode options: " + message,
+			}
+
+			match := optionErrorLine.FindStringSubmatch(message)
+			if match != nil {
+				line, _ := strconv.Atoi(match[1])
+				issue.Path = paths[line]
+				issue.Message = "cannot decode options: " + match[2]
+			}
+
+			issues = append(issues, issue)
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Path < issues[j].Path
+	})
+
+	list := make(MultiError, len(issues))
+	for i := range issues {
+		list[i] = issues[i]
+	}
+
+	return list
+}
+
+// optionErrorLine matches the line number of an error returned by the yaml decoder.
+var optionErrorLine = regexp.MustCompile(`^line ([0-9]+): (.*)$`)
+
+// copyOptionsNode copies given node, and numbers its nodes with a unique line, whose key path is added to given
+// paths.
+func copyOptionsNode(node *yaml.Node, path string, paths map[int]string) *yaml.Node {
+	copied := *node
+	copied.Line = len(paths) + 1
+	copied.Column = 0
+	paths[copied.Line] = path
+
+	if node.Alias != nil {
+		copied.Alias = copyOptionsNode(node.Alias, path, paths)
+	}
+
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i := range node.Content {
+		switch node.Kind {
+		case yaml.MappingNode:
+			key := node.Content[i-i%2].Value
+			copied.Content[i] = copyOptionsNode(node.Content[i], joinPath(path, key), paths)
+
+		case yaml.SequenceNode:
+			copied.Content[i] = copyOptionsNode(node.Content[i], indexPath(path, i), paths)
+
+		default:
+			copied.Content[i] = copyOptionsNode(node.Content[i], path, paths)
+		}
+	}
+
+	return &copied
+}
+
+// getOptionsIssues returns the issues of given error returned by a validation hook, whose paths are relative to
+// the options, or nil if it's not composed of issues.
+func getOptionsIssues(err error) []*ConfigIssue {
+	switch err := errors.Cause(err).(type) {
+	case *ConfigIssue:
+		return []*ConfigIssue{err}
+
+	case MultiError:
+		issues := make([]*ConfigIssue, 0, len(err))
+		for i := range err {
+			issue, ok := errors.Cause(err[i]).(*ConfigIssue)
+			if !ok {
+				return nil
+			}
+			issues = append(issues, issue)
+		}
+		return issues
+
+	default:
+		return nil
+	}
+}
+
+// A ConfigSchema describes the layout of the entries written by an appender.
+// It could be defined with a preset name, such as "ecs", or with a preset and some overrides.
+type ConfigSchema struct {
+	// Preset defines the schema used as base. Could be "soba", "ecs", "gcp" or "gelf". By default, it's "soba".
+	Preset string `yaml:"preset"`
+	// LoggerKey overrides the key used for the entry name.
+	LoggerKey string `yaml:"logger_key"`
+	// TimeKey overrides the key used for the entry timestamp.
+	TimeKey string `yaml:"time_key"`
+	// LevelKey overrides the key used for the entry level.
+	LevelKey string `yaml:"level_key"`
+	// MessageKey overrides the key used for the entry message.
+	MessageKey string `yaml:"message_key"`
+	// OmitLogger removes the entry name.
+	OmitLogger bool `yaml:"omit_logger"`
+	// LevelFormat overrides how the entry level is written. Could be "lower", "upper" or "syslog".
+	LevelFormat string `yaml:"level_format"`
+	// TimeFormat overrides how the entry timestamp is written. Could be "rfc3339" or "unix".
+	TimeFormat string `yaml:"time_format"`
+}
+
+// UnmarshalYAML decodes a schema configuration, either from a preset name or from an object.
+func (conf *ConfigSchema) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		preset := ""
+		err := value.Decode(&preset)
+		if err != nil {
+			return err
+		}
+		*conf = ConfigSchema{Preset: preset}
+		return nil
+	}
+
+	type raw ConfigSchema
+	return value.Decode((*raw)(conf))
 }
 
 // CheckPath verifies that given path is valid.
@@ -66,19 +347,61 @@ func CheckPath(path string) bool {
 }
 
 // ParseConfig parses a file path and creates a new Config.
+// Its format is selected by its extension: ".json" for JSON, ".toml" for TOML, and YAML otherwise.
+//
+// A configuration file could use variables in its values, such as "${HOSTNAME}" or "${LEVEL:-info}", merge other
+// files with an "include" directive, and declare named profiles, selected by EnvConfigProfile:
+//
+//	include:
+//	  - common.yml
+//	root:
+//	  level: ${LEVEL:-info}
+//	profiles:
+//	  dev:
+//	    root:
+//	      level: debug
+//
+// Then, it's overridden by environment variables, as described by EnvConfigPrefix.
+//
+// Every problem of the configuration is reported, with its key path and line number, using a MultiError of
+// ConfigIssue. An unknown key is only reported as a warning: use ParseConfigStrict to reject it.
 func ParseConfig(path string) (*Config, error) {
+	return parseConfig(path, false)
+}
+
+// ParseConfigStrict parses a file path and creates a new Config, like ParseConfig, but rejects every key of the
+// configuration file that doesn't match any property, such as a misspelled one.
+func ParseConfigStrict(path string) (*Config, error) {
+	return parseConfig(path, true)
+}
+
+func parseConfig(path string, strict bool) (*Config, error) {
 	conf := &Config{}
 
-	buffer, err := ioutil.ReadFile(path)
+	tree, positions, err := loadDocument(path, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	err = applyDocumentProfile(tree, positions, os.Getenv(EnvConfigProfile))
 	if err != nil {
-		return nil, errors.Wrap(err, "cannot read configuration file")
+		return nil, errors.Wrap(err, "cannot apply configuration profile")
 	}
 
-	err = yaml.Unmarshal(buffer, conf)
+	err = unmarshalDocument(tree, conf)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse configuration file")
 	}
 
+	conf.positions = positions
+	conf.unknownKeys = findUnknownKeys(tree, reflect.TypeOf(conf), "")
+	conf.strict = strict
+
+	err = ApplyEnvConfig(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot override configuration file")
+	}
+
 	err = ValidateConfig(conf)
 	if err != nil {
 		return nil, errors.Wrap(err, "configuration file is invalid")
@@ -88,6 +411,10 @@ func ParseConfig(path string) (*Config, error) {
 }
 
 // ValidateConfig verifies that given configuration is valid.
+// Its appender types should be either built-in or registered with RegisterAppenderType.
+//
+// Every problem is reported using a MultiError of ConfigIssue, and the problems that don't prevent the
+// configuration to be used are available with Config.Warnings.
 func ValidateConfig(conf *Config) error {
 	if conf == nil {
 		return errors.Errorf("given configuration is empty")
@@ -96,24 +423,37 @@ func ValidateConfig(conf *Config) error {
 		return nil
 	}
 
-	err := validateAppendersConfig(conf)
+	err := validateConfig(conf, defaultRegistry)
 	if err != nil {
 		return err
 	}
 
-	err = validateRootLoggerConfig(conf)
-	if err != nil {
-		return err
-	}
+	conf.verified = true
 
-	err = validateLoggersConfig(conf)
-	if err != nil {
-		return err
+	return nil
+}
+
+// validateConfig verifies that given configuration is valid, using appender types of given registry.
+func validateConfig(conf *Config, registry *Registry) error {
+	if conf == nil {
+		return errors.Errorf("given configuration is empty")
 	}
 
-	conf.verified = true
+	validator := newConfigValidator(conf)
 
-	return nil
+	validateKeysConfig(conf, validator)
+	validateRedactConfig(conf.Redact, validator)
+	validateFieldsConfig(conf, validator)
+	validateAppendersConfig(conf, registry, validator)
+	validateRootLoggerConfig(conf, validator)
+	validateLoggersConfig(conf, validator)
+	validateUnknownKeysConfig(conf, validator)
+	validateDeadConfig(conf, validator)
+
+	sortConfigIssues(validator.warnings)
+	conf.warnings = validator.warnings
+
+	return validator.err()
 }
 
 // NewDefaultConfig returns a default configuration.
@@ -138,91 +478,269 @@ func NewDefaultConfig() *Config {
 	}
 }
 
-func validateRootLoggerConfig(conf *Config) error {
+func onConfigWarnings(conf *Config) {
+	for _, warning := range conf.Warnings() {
+		// We choose to ignore the error if we cannot log it on stderr.
+		_, _ = fmt.Fprintln(os.Stderr, "soba: warning:", warning.Error())
+	}
+}
 
-	conf.Root.Additive = false
+// newEnvDefaultConfig returns a default configuration overridden by environment variables.
+func newEnvDefaultConfig() (*Config, error) {
+	conf := NewDefaultConfig()
 
-	if !IsLevelNameValid(conf.Root.Level) {
-		return errors.Errorf("level is invalid for root logger")
+	err := ApplyEnvConfig(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot override default configuration")
 	}
 
-	if len(conf.Root.Appenders) == 0 {
-		return errors.Errorf("one appender is required for root logger")
+	return conf, nil
+}
+
+func validateKeysConfig(conf *Config, validator *configValidator) {
+
+	if !IsKeyPolicyNameValid(conf.Keys.Policy) {
+		validator.errorf("keys.policy", `unknown key policy "%s"%s`, conf.Keys.Policy,
+			suggest(conf.Keys.Policy, keyPolicyNames))
 	}
+}
 
-	for _, appender := range conf.Root.Appenders {
-		if !conf.IsAppenderExists(appender) {
-			return errors.Errorf("appender is invalid for root logger")
+func validateRedactConfig(conf ConfigRedact, validator *configValidator) {
+
+	for i, rule := range conf.Keys {
+		location := indexPath("redact.keys", i)
+
+		_, err := path.Match(rule.Pattern, "")
+		if rule.Pattern == "" {
+			validator.errorf(joinPath(location, "pattern"), "key pattern is required")
+		} else if err != nil {
+			validator.errorf(joinPath(location, "pattern"), `invalid key pattern "%s": %s`, rule.Pattern, err)
+		}
+
+		switch rule.Action {
+		case MaskRedactAction, DropRedactAction, "":
+		default:
+			validator.errorf(joinPath(location, "action"), `unknown action "%s"%s`, rule.Action,
+				suggest(rule.Action, []string{MaskRedactAction, DropRedactAction}))
 		}
 	}
 
-	return nil
-}
+	for i, rule := range conf.Values {
+		location := joinPath(indexPath("redact.values", i), "pattern")
 
-func validateLoggersConfig(conf *Config) error {
+		_, err := regexp.Compile(rule.Pattern)
+		if rule.Pattern == "" {
+			validator.errorf(location, "value pattern is required")
+		} else if err != nil {
+			validator.errorf(location, `invalid value pattern "%s": %s`, rule.Pattern, err)
+		}
+	}
+}
 
-	for name, logger := range conf.Loggers {
+func validateFieldsConfig(conf *Config, validator *configValidator) {
 
-		if !IsLevelNameValid(logger.Level) {
-			return errors.Errorf("level is invalid for logger: %s", name)
+	for i, name := range conf.Builtins {
+		if !IsBuiltinFieldNameValid(name) {
+			validator.errorf(indexPath("builtins", i), `unknown builtin field "%s"%s`, name,
+				suggest(name, builtinFieldNames))
 		}
+	}
 
-		if !IsLoggerNameValid(name) {
-			return errors.Errorf("name is invalid for logger: %s", name)
+	if _, ok := conf.Fields[""]; ok {
+		validator.errorf("fields", "field key is empty")
+	}
+
+	if _, ok := conf.Root.Fields[""]; ok {
+		validator.errorf("root.fields", "field key is empty")
+	}
+
+	for name, logger := range conf.Loggers {
+		if _, ok := logger.Fields[""]; ok {
+			validator.errorf(joinPath(joinPath("loggers", name), "fields"), "field key is empty")
 		}
+	}
+}
 
-		for _, appender := range logger.Appenders {
-			if !conf.IsAppenderExists(appender) {
-				return errors.Errorf("appender is invalid for logger: %s", name)
-			}
+func validateRootLoggerConfig(conf *Config, validator *configValidator) {
+
+	conf.Root.Additive = false
+
+	// Since root logger has no ancestor, it cannot inherit its level.
+	if conf.Root.Level == "" {
+		validator.errorf("root.level", "level is required")
+	} else {
+		validateLoggerLevel(conf.Root, "root", validator)
+	}
+
+	if len(conf.Root.Appenders) == 0 {
+		validator.errorf("root.appenders", "one appender is required for root logger")
+	}
+
+	validateLoggerAppenders(conf, conf.Root, "root", validator)
+}
+
+func validateLoggersConfig(conf *Config, validator *configValidator) {
+
+	for name, logger := range conf.Loggers {
+		path := joinPath("loggers", name)
+
+		if !IsLoggerNameValid(name) {
+			validator.errorf(path, `invalid logger name "%s"`, name)
 		}
 
+		validateLoggerLevel(logger, path, validator)
+		validateLoggerAppenders(conf, logger, path, validator)
 	}
+}
 
-	return nil
+// validateLoggerLevel verifies the level of given logger. If omitted, the level is inherited from its nearest
+// ancestor that defines one.
+func validateLoggerLevel(logger ConfigLogger, path string, validator *configValidator) {
+
+	if logger.Level != "" && !IsLevelNameValid(logger.Level) {
+		validator.errorf(joinPath(path, "level"), `unknown level "%s"%s`, logger.Level,
+			suggest(logger.Level, levelNames))
+	}
 }
 
-func validateAppendersConfig(conf *Config) error {
+func validateLoggerAppenders(conf *Config, logger ConfigLogger, path string, validator *configValidator) {
 
-	for name, appender := range conf.Appenders {
-		err := validateAppenderConfig(name, appender)
-		if err != nil {
-			return err
+	for i, appender := range logger.Appenders {
+		if !conf.IsAppenderExists(appender) {
+			validator.errorf(indexPath(joinPath(path, "appenders"), i), `unknown appender "%s"%s`, appender,
+				suggest(appender, appenderNames(conf)))
 		}
 	}
+}
 
-	return nil
+func validateAppendersConfig(conf *Config, registry *Registry, validator *configValidator) {
+
+	for name, appender := range conf.Appenders {
+		validateAppenderConfig(name, appender, registry, validator)
+	}
 }
 
-func validateAppenderConfig(name string, conf ConfigAppender) error {
+// nolint: gocyclo
+func validateAppenderConfig(name string, conf ConfigAppender, registry *Registry, validator *configValidator) {
+
+	path := joinPath("appenders", name)
 
 	if !IsAppenderNameValid(name) {
-		return errors.Errorf("name is invalid for appender: %s", name)
+		validator.errorf(path, `invalid appender name "%s"`, name)
+	}
+
+	if !IsEncoderTypeValid(conf.Encoder) {
+		validator.errorf(joinPath(path, "encoder"), `unknown encoder "%s"%s`, conf.Encoder,
+			suggest(conf.Encoder, []string{JSONEncoderType, CBOREncoderType}))
+	}
+
+	validateSchemaConfig(conf.Schema, joinPath(path, "schema"), validator)
+
+	switch conf.Type {
+	case ConsoleAppenderType, FileAppenderType:
+		if !conf.Options.IsEmpty() {
+			validator.errorf(joinPath(path, "options"), `options are not supported by appender type "%s"`,
+				conf.Type)
+		}
 	}
 
 	switch conf.Type {
 	case ConsoleAppenderType:
 		if conf.Path != "" {
-			return errors.Errorf("path is not required for appender: %s", name)
+			validator.errorf(joinPath(path, "path"), `path is not supported by appender type "%s"`, conf.Type)
 		}
 		if conf.Backup {
-			return errors.Errorf("backup is not required for appender: %s", name)
+			validator.errorf(joinPath(path, "backup"), `backup is not supported by appender type "%s"`, conf.Type)
 		}
 		if conf.MaxBytes > 0 {
-			return errors.Errorf("max bytes is not required for appender: %s", name)
+			validator.errorf(joinPath(path, "max_bytes"), `max bytes is not supported by appender type "%s"`,
+				conf.Type)
 		}
 
 	case FileAppenderType:
 		if conf.Path == "" {
-			return errors.Errorf("path is invalid for appender: %s", name)
+			validator.errorf(joinPath(path, "path"), "path is required")
 		}
 		if conf.MaxBytes < 0 {
-			return errors.Errorf("max bytes is invalid for appender: %s", name)
+			validator.errorf(joinPath(path, "max_bytes"), "max bytes should be positive: %d", conf.MaxBytes)
 		}
 
+	case "":
+		validator.errorf(joinPath(path, "type"), "type is required")
+
 	default:
-		return errors.Errorf("type is invalid for appender: %s", name)
+		if !registry.IsAppenderTypeRegistered(conf.Type) {
+			validator.errorf(joinPath(path, "type"), `unknown appender type "%s"%s`, conf.Type,
+				suggest(conf.Type, registry.types()))
+			return
+		}
+
+		err := registry.validate(name, conf)
+		if err == nil {
+			return
+		}
+
+		issues := getOptionsIssues(err)
+		if issues == nil {
+			validator.errorf(path, "%s", err)
+			return
+		}
+
+		for _, issue := range issues {
+			validator.errorf(joinPath(joinPath(path, "options"), issue.Path), "%s", issue.Message)
+		}
 	}
+}
 
-	return nil
+func validateUnknownKeysConfig(conf *Config, validator *configValidator) {
+
+	for _, issue := range conf.unknownKeys {
+		if conf.strict {
+			validator.errorf(issue.Path, "%s", issue.Message)
+		} else {
+			validator.warnf(issue.Path, "%s", issue.Message)
+		}
+	}
+}
+
+// validateDeadConfig reports the configuration that has no effect, such as an appender referenced by no logger.
+func validateDeadConfig(conf *Config, validator *configValidator) {
+
+	used := map[string]bool{}
+	for _, name := range conf.Root.Appenders {
+		used[name] = true
+	}
+
+	for name, logger := range conf.Loggers {
+		for _, appender := range logger.Appenders {
+			used[appender] = true
+		}
+
+		level, ok := ParseLevel(logger.Level)
+		if ok && level == NoLevel && len(logger.Appenders) > 0 {
+			validator.warnf(joinPath(joinPath("loggers", name), "appenders"),
+				"appenders are ignored since logger is disabled")
+		}
+	}
+
+	for name := range conf.Appenders {
+		if !used[name] {
+			validator.warnf(joinPath("appenders", name), `appender "%s" is not used by any logger`, name)
+		}
+	}
+}
+
+// levelNames is the list of level names suggested for an invalid level.
+var levelNames = []string{
+	strDebugLevel, strInfoLevel, strWarnLevel, strShortWarnLevel, strErrorLevel, strNoLevelNone,
+}
+
+// keyPolicyNames is the list of key policy names suggested for an invalid key policy.
+var keyPolicyNames = []string{
+	strPreserveKeyPolicy, strLowerKeyPolicy, strSnakeCaseKeyPolicy, strCamelCaseKeyPolicy,
+}
+
+// builtinFieldNames is the list of builtin field names suggested for an invalid builtin field.
+var builtinFieldNames = []string{
+	HostnameBuiltinField, PidBuiltinField, VersionBuiltinField,
 }
@@ -1,6 +1,10 @@
 package soba_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/novln/soba"
@@ -51,6 +55,19 @@ func TestConfig_ParseConfig(t *testing.T) {
 			t.Fatalf("A configuration was expected for %s", path)
 		}
 	}
+	{
+		path := "testdata/redact.yaml"
+		conf, err := soba.ParseConfig(path)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %+v", path, err)
+		}
+		if len(conf.Redact.Keys) != 2 || conf.Redact.Keys[1].Action != soba.DropRedactAction {
+			t.Fatalf("Unexpected redact keys for %s: %+v", path, conf.Redact.Keys)
+		}
+		if len(conf.Redact.Values) != 1 || conf.Redact.Values[0].Mask != "[CARD]" {
+			t.Fatalf("Unexpected redact values for %s: %+v", path, conf.Redact.Values)
+		}
+	}
 	{
 		path := "testdata/invalid.yaml"
 		conf, err := soba.ParseConfig(path)
@@ -93,6 +110,65 @@ func TestConfig_ParseConfig(t *testing.T) {
 	}
 }
 
+// CompareConfig verifies that every exported field of given configuration matches the expected one.
+func CompareConfig(t *testing.T, path string, conf *soba.Config, expected *soba.Config) {
+	value := reflect.ValueOf(*conf)
+	other := reflect.ValueOf(*expected)
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if !reflect.DeepEqual(value.Field(i).Interface(), other.Field(i).Interface()) {
+			t.Fatalf("Unexpected %s for %s: %+v should be %+v", field.Name, path,
+				value.Field(i).Interface(), other.Field(i).Interface())
+		}
+	}
+}
+
+// Test parsing of a configuration file with JSON and TOML formats.
+func TestConfig_ParseConfigFormats(t *testing.T) {
+	for _, name := range []string{"testdata/simple", "testdata/full"} {
+		expected, err := soba.ParseConfig(name + ".yaml")
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %+v", name, err)
+		}
+
+		for _, path := range []string{name + ".json", name + ".toml"} {
+			conf, err := soba.ParseConfig(path)
+			if err != nil {
+				t.Fatalf("Unexpected error for %s: %+v", path, err)
+			}
+			CompareConfig(t, path, conf, expected)
+		}
+	}
+
+	directory, err := ioutil.TempDir("", "soba")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(directory)
+	}()
+
+	for _, name := range []string{"invalid.json", "invalid.toml"} {
+		path := filepath.Join(directory, name)
+		err = ioutil.WriteFile(path, []byte("root = {"), 0600)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		conf, err := soba.ParseConfig(path)
+		if err == nil {
+			t.Fatalf("An error was expected for %s", path)
+		}
+		if conf != nil {
+			t.Fatalf("Unexpected configuration for %s", path)
+		}
+	}
+}
+
 // Test validation of a configuration file.
 // nolint: gocyclo
 func TestConfig_ValidateConfig(t *testing.T) {
@@ -215,6 +291,49 @@ func TestConfig_ValidateConfig(t *testing.T) {
 			},
 		}
 
+		err := soba.ValidateConfig(conf)
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+	}
+	{
+		conf := soba.NewDefaultConfig()
+		conf.Keys.Policy = "kebab_case"
+
+		err := soba.ValidateConfig(conf)
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+	}
+	{
+		conf := soba.NewDefaultConfig()
+		conf.Redact.Keys = []soba.ConfigRedactKey{
+			{
+				Pattern: "password",
+				Action:  "hide",
+			},
+		}
+
+		err := soba.ValidateConfig(conf)
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+	}
+	{
+		conf := soba.NewDefaultConfig()
+		conf.Builtins = []string{"uptime"}
+
+		err := soba.ValidateConfig(conf)
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+	}
+	{
+		conf := soba.NewDefaultConfig()
+		conf.Fields = map[string]interface{}{
+			"": "foobar",
+		}
+
 		err := soba.ValidateConfig(conf)
 		if err == nil {
 			t.Fatal("An error was expected")
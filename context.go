@@ -18,6 +18,8 @@ var hCtxKey = &ctxKey{}
 //  - Then, it will lookup from current directory if a configuration file exists.
 //  - Finally, it will create a new instance with default configurations.
 //
+// In every case, the configuration is overridden by environment variables, as described by EnvConfigPrefix.
+//
 // For specific configurations, please uses either LoadWithConfig or LoadWithFile.
 func Load(ctx context.Context) (context.Context, error) {
 	path := os.Getenv(EnvConfigPath)
@@ -29,7 +31,12 @@ func Load(ctx context.Context) (context.Context, error) {
 		return LoadWithFile(ctx, DefaultConfigPath)
 	}
 
-	return LoadWithConfig(ctx, NewDefaultConfig())
+	conf, err := newEnvDefaultConfig()
+	if err != nil {
+		return ctx, err
+	}
+
+	return LoadWithConfig(ctx, conf)
 }
 
 // LoadWithConfig returns a new context with a soba instance using given configuration.
@@ -39,7 +46,7 @@ func LoadWithConfig(ctx context.Context, config *Config) (context.Context, error
 		return ctx, errors.Wrap(err, "configuration is invalid")
 	}
 
-	handler, err := create(config)
+	handler, err := create(config, defaultRegistry)
 	if err != nil {
 		return ctx, err
 	}
@@ -49,10 +56,13 @@ func LoadWithConfig(ctx context.Context, config *Config) (context.Context, error
 }
 
 // LoadWithFile returns a new context with a soba instance using given file path.
+// The warnings of its configuration are written on stderr.
 func LoadWithFile(ctx context.Context, path string) (context.Context, error) {
 	conf, err := ParseConfig(path)
 	if err != nil {
 		return ctx, err
 	}
+
+	onConfigWarnings(conf)
 	return LoadWithConfig(ctx, conf)
 }
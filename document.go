@@ -0,0 +1,463 @@
+package soba
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// EnvConfigProfile defines the environment variable that selects a profile of a configuration file.
+const EnvConfigProfile = "SOBA_PROFILE"
+
+const (
+	// includeDocumentKey defines the directive that merges other configuration files, using either a path or a
+	// list of paths, relative to the configuration file.
+	includeDocumentKey = "include"
+	// profilesDocumentKey defines the named profiles of a configuration file.
+	profilesDocumentKey = "profiles"
+)
+
+// interpolationPattern matches a variable, such as "${HOSTNAME}" or "${LEVEL:-info}", or an escaped "$" with "$$".
+var interpolationPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// A document is a configuration file decoded as a generic tree, before it's converted to a Config.
+//
+// The document of a configuration file is assembled with the following precedence, from lowest to highest:
+//   - First, its included files, in order.
+//   - Then, the configuration file itself.
+//   - Finally, its profile selected by EnvConfigProfile, if any.
+//
+// A map is merged with the map it overrides, whereas any other value, such as a list, replaces it.
+type document map[string]interface{}
+
+// loadDocument reads the configuration file of given path, and the files it includes.
+// Every variable of its values is interpolated using given lookup function.
+// It also returns where its keys are declared, for YAML files.
+func loadDocument(path string, lookup func(string) (string, bool)) (document, map[string]position, error) {
+	return loadDocumentWithParents(path, lookup, nil)
+}
+
+func loadDocumentWithParents(path string, lookup func(string) (string, bool),
+	parents []string) (document, map[string]position, error) {
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	for _, parent := range parents {
+		if parent == absolute {
+			return nil, nil, errors.Errorf("include cycle detected: %s", strings.Join(append(parents, absolute), " -> "))
+		}
+	}
+	parents = append(parents, absolute)
+
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot read configuration file")
+	}
+
+	tree, positions, err := decodeDocument(path, buffer)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot parse configuration file %s", path)
+	}
+
+	value, err := interpolateValue(tree, "", lookup)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot interpolate configuration file %s", path)
+	}
+	tree = value.(document)
+
+	includes, err := getDocumentIncludes(tree)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "cannot parse configuration file %s", path)
+	}
+	delete(tree, includeDocumentKey)
+
+	result := document{}
+	others := map[string]position{}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		other, locations, err := loadDocumentWithParents(include, lookup, parents)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mergeDocument(result, other)
+		for key, value := range locations {
+			others[key] = value
+		}
+	}
+
+	// The keys of the configuration file are located in this file, rather than in its included files.
+	mergeDocument(result, tree)
+	for key, value := range positions {
+		others[key] = value
+	}
+
+	return result, others, nil
+}
+
+func indexNodePositions(path string, node *yaml.Node, key string, positions map[string]position) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := joinPath(key, node.Content[i].Value)
+			positions[name] = position{file: path, line: node.Content[i].Line}
+			indexNodePositions(path, node.Content[i+1], name, positions)
+		}
+
+	case yaml.SequenceNode:
+		for i := range node.Content {
+			name := indexPath(key, i)
+			positions[name] = position{file: path, line: node.Content[i].Line}
+			indexNodePositions(path, node.Content[i], name, positions)
+		}
+
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			indexNodePositions(path, node.Alias, key, positions)
+		}
+	}
+}
+
+// decodeDocument decodes given buffer, using the format of the extension of given path.
+// It also returns the line of every key path declared in given buffer, such as "root.level", which is only
+// available for YAML files.
+func decodeDocument(path string, buffer []byte) (document, map[string]position, error) {
+	var tree interface{}
+	positions := map[string]position{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err := json.Unmarshal(buffer, &tree)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+	case ".toml":
+		table := map[string]interface{}{}
+		err := toml.Unmarshal(buffer, &table)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		tree = table
+
+	default:
+		node := yaml.Node{}
+		err := yaml.Unmarshal(buffer, &node)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		if len(node.Content) == 0 {
+			break
+		}
+
+		err = node.Decode(&tree)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		positions[""] = position{file: path}
+		indexNodePositions(path, node.Content[0], "", positions)
+	}
+
+	if tree == nil {
+		return document{}, positions, nil
+	}
+
+	value, ok := normalizeValue(tree).(document)
+	if !ok {
+		return nil, nil, errors.New("configuration should be a map")
+	}
+
+	return value, positions, nil
+}
+
+// normalizeValue converts the maps decoded by every format to a document, so they could be merged.
+func normalizeValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		tree := make(document, len(value))
+		for key, element := range value {
+			tree[fmt.Sprint(key)] = normalizeValue(element)
+		}
+		return tree
+
+	case map[string]interface{}:
+		tree := make(document, len(value))
+		for key, element := range value {
+			tree[key] = normalizeValue(element)
+		}
+		return tree
+
+	case []map[string]interface{}:
+		list := make([]interface{}, len(value))
+		for i := range value {
+			list[i] = normalizeValue(value[i])
+		}
+		return list
+
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i := range value {
+			list[i] = normalizeValue(value[i])
+		}
+		return list
+
+	default:
+		return value
+	}
+}
+
+// interpolateValue replaces every variable of the strings in given value, whose key path is used to report an error.
+func interpolateValue(value interface{}, path string, lookup func(string) (string, bool)) (interface{}, error) {
+	switch value := value.(type) {
+	case document:
+		for key, element := range value {
+			element, err := interpolateValue(element, joinPath(path, key), lookup)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = element
+		}
+		return value, nil
+
+	case []interface{}:
+		for i := range value {
+			element, err := interpolateValue(value[i], indexPath(path, i), lookup)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = element
+		}
+		return value, nil
+
+	case string:
+		result, err := interpolateString(value, lookup)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for %s", path)
+		}
+		return result, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// interpolateString replaces every variable of given string. A variable without default value must be defined.
+// The result is always a string, such as "1.10" or "0123": it's only converted by the property it's assigned to, if
+// this property is a boolean or a number, as described by coerceDocumentScalars.
+func interpolateString(value string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var thr error
+	result := interpolationPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		submatches := interpolationPattern.FindStringSubmatch(match)
+		name, fallback, hasFallback := submatches[1], submatches[3], submatches[2] != ""
+
+		variable, ok := lookup(name)
+		if ok && (variable != "" || !hasFallback) {
+			return variable
+		}
+		if hasFallback {
+			return fallback
+		}
+
+		if thr == nil {
+			thr = errors.Errorf("variable is not defined: %s", name)
+		}
+		return ""
+	})
+	if thr != nil {
+		return "", thr
+	}
+
+	return result, nil
+}
+
+// coerceDocumentScalars resets the tag of the string scalars of given node whose property, from given type, is a
+// boolean or a number: they are decoded using the YAML scalar rules, so "true" or "3" are accepted. Otherwise, a
+// string scalar is kept as a string, even for a free-form value like a static field.
+func coerceDocumentScalars(node *yaml.Node, kind reflect.Type) {
+	for kind.Kind() == reflect.Ptr {
+		kind = kind.Elem()
+	}
+
+	if kind == reflect.TypeOf(ConfigOptions{}) {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for i := range node.Content {
+			coerceDocumentScalars(node.Content[i], kind)
+		}
+
+	case yaml.MappingNode:
+		switch kind.Kind() {
+		case reflect.Struct:
+			fields := map[string]reflect.Type{}
+			for i := 0; i < kind.NumField(); i++ {
+				field := kind.Field(i)
+				name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+				if field.PkgPath == "" && name != "" && name != "-" {
+					fields[name] = field.Type
+				}
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				field, ok := fields[node.Content[i].Value]
+				if ok {
+					coerceDocumentScalars(node.Content[i+1], field)
+				}
+			}
+
+		case reflect.Map:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				coerceDocumentScalars(node.Content[i+1], kind.Elem())
+			}
+		}
+
+	case yaml.SequenceNode:
+		if kind.Kind() == reflect.Slice {
+			for i := range node.Content {
+				coerceDocumentScalars(node.Content[i], kind.Elem())
+			}
+		}
+
+	case yaml.ScalarNode:
+		switch kind.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if node.ShortTag() == "!!str" {
+				node.Tag = ""
+				node.Style = 0
+			}
+		}
+	}
+}
+
+// getDocumentIncludes returns the paths of files included by given document.
+func getDocumentIncludes(tree document) ([]string, error) {
+	switch value := tree[includeDocumentKey].(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		return []string{value}, nil
+
+	case []interface{}:
+		includes := make([]string, 0, len(value))
+		for _, element := range value {
+			include, ok := element.(string)
+			if !ok || include == "" {
+				return nil, errors.Errorf("include is invalid: %v", element)
+			}
+			includes = append(includes, include)
+		}
+		return includes, nil
+
+	default:
+		return nil, errors.Errorf("include is invalid: %v", value)
+	}
+}
+
+// applyDocumentProfile merges the profile of given name into given document, and removes its profiles.
+// The keys of the profile are then located where they are declared in the profile.
+// If given name is empty, no profile is applied.
+func applyDocumentProfile(tree document, positions map[string]position, name string) error {
+	profiles := document{}
+	if value, ok := tree[profilesDocumentKey]; ok && value != nil {
+		profiles, ok = value.(document)
+		if !ok {
+			return errors.New("profiles should be a map")
+		}
+	}
+	delete(tree, profilesDocumentKey)
+
+	prefix := joinPath(profilesDocumentKey, name) + "."
+	rebased := map[string]position{}
+	for key, value := range positions {
+		if strings.HasPrefix(key, profilesDocumentKey+".") || key == profilesDocumentKey {
+			delete(positions, key)
+			if name != "" && strings.HasPrefix(key, prefix) {
+				rebased[strings.TrimPrefix(key, prefix)] = value
+			}
+		}
+	}
+	for key, value := range rebased {
+		positions[key] = value
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	value, ok := profiles[name]
+	if !ok {
+		return errors.Errorf("profile is not defined: %s%s", name, suggest(name, sortedDocumentKeys(profiles)))
+	}
+
+	if value == nil {
+		return nil
+	}
+
+	profile, ok := value.(document)
+	if !ok {
+		return errors.Errorf("profile should be a map: %s", name)
+	}
+
+	mergeDocument(tree, profile)
+
+	return nil
+}
+
+// mergeDocument merges given source into given destination.
+func mergeDocument(destination document, source document) {
+	for key, value := range source {
+		other, ok := value.(document)
+		if ok {
+			current, ok := destination[key].(document)
+			if ok {
+				mergeDocument(current, other)
+				continue
+			}
+			copied := document{}
+			mergeDocument(copied, other)
+			value = copied
+		}
+		destination[key] = value
+	}
+}
+
+// unmarshalDocument decodes given document into given configuration.
+// It's converted to a YAML node, so every format shares the same keys and decoding rules.
+func unmarshalDocument(tree document, conf *Config) error {
+	node := yaml.Node{}
+	err := node.Encode(map[string]interface{}(tree))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	coerceDocumentScalars(&node, reflect.TypeOf(conf))
+
+	return errors.WithStack(node.Decode(conf))
+}
@@ -0,0 +1,163 @@
+package soba_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+// Test variable interpolation and includes of a configuration file.
+// nolint: gocyclo
+func TestDocument_Include(t *testing.T) {
+	t.Setenv(soba.EnvConfigProfile, "")
+	t.Setenv("SOBA_TEST_SERVICE", "api")
+	t.Setenv("SOBA_TEST_MAX_BYTES", "2048")
+
+	conf, err := soba.ParseConfig("testdata/include/service.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if conf.Root.Level != "warn" || len(conf.Root.Appenders) != 1 {
+		t.Fatalf("Unexpected root logger: %+v", conf.Root)
+	}
+	if conf.Loggers["app.backend.db"].Level != "info" || conf.Loggers["app.requests"].Level != "info" {
+		t.Fatalf("Unexpected loggers: %+v", conf.Loggers)
+	}
+
+	appender := conf.Appenders["requests"]
+	if appender.Path != "testdata/logs/requests.log" || appender.MaxBytes != 2048 {
+		t.Fatalf("Unexpected appender: %+v", appender)
+	}
+
+	if conf.Fields["service"] != "api" || conf.Fields["region"] != "eu-west-1" || conf.Fields["price"] != "$5" {
+		t.Fatalf("Unexpected fields: %+v", conf.Fields)
+	}
+}
+
+// Test that an interpolated value is kept as a string, unless its property is a boolean or a number.
+func TestDocument_InterpolationScalars(t *testing.T) {
+	t.Setenv(soba.EnvConfigProfile, "")
+	t.Setenv("SOBA_TEST_LEVEL", "no")
+	t.Setenv("SOBA_TEST_ADDITIVE", "yes")
+	t.Setenv("SOBA_TEST_MAX_BYTES", "1024")
+	t.Setenv("SOBA_TEST_BACKUP", "true")
+	t.Setenv("SOBA_TEST_ENABLED", "true")
+	t.Setenv("SOBA_TEST_REPLICAS", "3")
+	t.Setenv("SOBA_TEST_SWITCH", "off")
+	t.Setenv("SOBA_TEST_VERSION", "1.10")
+	t.Setenv("SOBA_TEST_SHA", "0123")
+
+	conf, err := soba.ParseConfig("testdata/include/scalars.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if conf.Root.Level != "no" {
+		t.Fatalf("Unexpected root level: %s should be %s", conf.Root.Level, "no")
+	}
+	if !conf.Loggers["app.requests"].Additive {
+		t.Fatalf("Unexpected additivity: %+v", conf.Loggers["app.requests"])
+	}
+	if conf.Appenders["file"].MaxBytes != 1024 || !conf.Appenders["file"].Backup {
+		t.Fatalf("Unexpected appender: %+v", conf.Appenders["file"])
+	}
+
+	expected := map[string]interface{}{
+		"enabled":  "true",
+		"replicas": "3",
+		"switch":   "off",
+		"version":  "1.10",
+		"sha":      "0123",
+		"literal":  3,
+	}
+
+	for key, value := range expected {
+		if conf.Fields[key] != value {
+			t.Fatalf("Unexpected field %s: %#v should be %#v", key, conf.Fields[key], value)
+		}
+	}
+}
+
+// Test profiles of a configuration file.
+func TestDocument_Profiles(t *testing.T) {
+	t.Setenv("SOBA_TEST_SERVICE", "api")
+	t.Setenv("SOBA_TEST_REGION", "")
+
+	scenarios := []struct {
+		profile   string
+		root      string
+		appenders int
+		db        string
+	}{
+		{profile: "", root: "warn", appenders: 1, db: "info"},
+		{profile: "dev", root: "debug", appenders: 1, db: "info"},
+		{profile: "prod", root: "error", appenders: 1, db: "warn"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Setenv(soba.EnvConfigProfile, scenario.profile)
+
+		conf, err := soba.ParseConfig("testdata/include/service.yaml")
+		if err != nil {
+			t.Fatalf("Unexpected error for profile %s: %+v", scenario.profile, err)
+		}
+		if conf.Root.Level != scenario.root || len(conf.Root.Appenders) != scenario.appenders {
+			t.Fatalf("Unexpected root logger for profile %s: %+v", scenario.profile, conf.Root)
+		}
+		if conf.Loggers["app.backend.db"].Level != scenario.db {
+			t.Fatalf("Unexpected logger for profile %s: %+v", scenario.profile, conf.Loggers["app.backend.db"])
+		}
+		if conf.Fields["region"] != "eu-west-1" {
+			t.Fatalf("Unexpected fields for profile %s: %+v", scenario.profile, conf.Fields)
+		}
+	}
+
+	// Environment variables override the selected profile.
+	t.Setenv(soba.EnvConfigProfile, "prod")
+	t.Setenv("SOBA_ROOT_LEVEL", "info")
+
+	conf, err := soba.ParseConfig("testdata/include/service.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if conf.Root.Level != "info" || conf.Root.Appenders[0] != "requests" {
+		t.Fatalf("Unexpected root logger: %+v", conf.Root)
+	}
+}
+
+// Test invalid includes, variables and profiles of a configuration file.
+func TestDocument_Invalid(t *testing.T) {
+	t.Setenv("SOBA_TEST_SERVICE", "api")
+	t.Setenv(soba.EnvConfigProfile, "")
+
+	scenarios := []struct {
+		path    string
+		profile string
+	}{
+		{path: "testdata/include/cycle.yaml"},
+		{path: "testdata/include/missing.yaml"},
+		{path: "testdata/include/service.yaml", profile: "staging"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Setenv(soba.EnvConfigProfile, scenario.profile)
+
+		_, err := soba.ParseConfig(scenario.path)
+		if err == nil {
+			t.Fatalf("An error was expected for %s with profile '%s'", scenario.path, scenario.profile)
+		}
+	}
+
+	t.Setenv(soba.EnvConfigProfile, "")
+	err := os.Unsetenv("SOBA_TEST_SERVICE")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	_, err = soba.ParseConfig("testdata/include/service.yaml")
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+}
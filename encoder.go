@@ -2,8 +2,40 @@ package soba
 
 import (
 	"github.com/novln/soba/encoder"
+	"github.com/novln/soba/encoder/cbor"
+	"github.com/novln/soba/encoder/json"
 )
 
+const (
+	// JSONEncoderType defines the type for a JSON encoder.
+	JSONEncoderType = "json"
+	// CBOREncoderType defines the type for a CBOR encoder, which is a compact binary format.
+	CBOREncoderType = "cbor"
+)
+
+// NewEncoder creates a new Encoder of given type, such as "json" or "cbor".
+// An empty type is considered as JSONEncoderType.
+func NewEncoder(kind string) (Encoder, bool) {
+	switch kind {
+	case JSONEncoderType, "":
+		return json.NewEncoder(), true
+	case CBOREncoderType:
+		return cbor.NewEncoder(), true
+	default:
+		return nil, false
+	}
+}
+
+// IsEncoderTypeValid verify that an encoder type is allowed.
+func IsEncoderTypeValid(kind string) bool {
+	switch kind {
+	case JSONEncoderType, CBOREncoderType, "":
+		return true
+	default:
+		return false
+	}
+}
+
 // Aliasing from github.com/novln/soba/encoder package to avoid circular imports.
 
 // Encoder is a strongly-typed, encoding-agnostic interface for adding array, map or struct-like object to the
@@ -11,6 +43,10 @@ import (
 // Also, be advised that Encoder aren't safe for concurrent use.
 type Encoder = encoder.Encoder
 
+// FragmentEncoder is an Encoder that can reuse pre-encoded object properties, called fragments.
+// A fragment is only valid for encoders with the same fragment type.
+type FragmentEncoder = encoder.FragmentEncoder
+
 // ArrayEncoder is a strongly-typed, encoding-agnostic interface for adding array to the logging context.
 // Also, be advised that Encoder aren't safe for concurrent use.
 type ArrayEncoder = encoder.ArrayEncoder
@@ -0,0 +1,97 @@
+package cbor
+
+// Major types of a CBOR data item, as defined by RFC 8949.
+const (
+	majorUnsigned = 0 << 5
+	majorNegative = 1 << 5
+	majorBytes    = 2 << 5
+	majorString   = 3 << 5
+	majorArray    = 4 << 5
+	majorMap      = 5 << 5
+	majorTag      = 6 << 5
+	majorSimple   = 7 << 5
+)
+
+// Additional informations of a CBOR data item header, as defined by RFC 8949.
+const (
+	additionalUint8      = 24
+	additionalUint16     = 25
+	additionalUint32     = 26
+	additionalUint64     = 27
+	additionalIndefinite = 31
+)
+
+// Simple values and floating-point numbers, as defined by RFC 8949.
+const (
+	simpleFalse     = majorSimple | 20
+	simpleTrue      = majorSimple | 21
+	simpleNull      = majorSimple | 22
+	simpleUndefined = majorSimple | 23
+	simpleFloat16   = majorSimple | additionalUint16
+	simpleFloat32   = majorSimple | additionalUint32
+	simpleFloat64   = majorSimple | additionalUint64
+	simpleBreak     = majorSimple | additionalIndefinite
+)
+
+// Tags used for times and durations.
+const (
+	// tagDateTime is a RFC3339 string.
+	tagDateTime = 0
+	// tagEpochDateTime is a number of seconds since the Unix epoch.
+	tagEpochDateTime = 1
+	// tagExtendedTime is a map with a number of seconds since the Unix epoch, and a fraction of second.
+	tagExtendedTime = 1001
+	// tagDuration is a map with a number of seconds, and a fraction of second.
+	tagDuration = 1002
+)
+
+// Keys of the map used by tagExtendedTime and tagDuration.
+const (
+	timeKeySeconds     = 1
+	timeKeyNanoseconds = -9
+)
+
+// AppendBeginMarker inserts a map start into the internal buffer.
+func (encoder *Encoder) AppendBeginMarker() {
+	encoder.buffer = append(encoder.buffer, majorMap|additionalIndefinite)
+}
+
+// AppendEndMarker inserts a map end into the internal buffer.
+func (encoder *Encoder) AppendEndMarker() {
+	encoder.buffer = append(encoder.buffer, simpleBreak)
+}
+
+// AppendArrayStart adds markers to indicate the start of an array.
+func (encoder *Encoder) AppendArrayStart() {
+	encoder.buffer = append(encoder.buffer, majorArray|additionalIndefinite)
+}
+
+// AppendArrayEnd adds markers to indicate the end of an array.
+func (encoder *Encoder) AppendArrayEnd() {
+	encoder.buffer = append(encoder.buffer, simpleBreak)
+}
+
+// AppendKey appends a new key into the internal buffer.
+func (encoder *Encoder) AppendKey(key string) {
+	encoder.AppendString(key)
+}
+
+// appendHeader appends the header of a data item, with given major type and argument.
+func (encoder *Encoder) appendHeader(major byte, value uint64) {
+	switch {
+	case value < additionalUint8:
+		encoder.buffer = append(encoder.buffer, major|byte(value))
+	case value <= 0xff:
+		encoder.buffer = append(encoder.buffer, major|additionalUint8, byte(value))
+	case value <= 0xffff:
+		encoder.buffer = append(encoder.buffer, major|additionalUint16,
+			byte(value>>8), byte(value))
+	case value <= 0xffffffff:
+		encoder.buffer = append(encoder.buffer, major|additionalUint32,
+			byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
+	default:
+		encoder.buffer = append(encoder.buffer, major|additionalUint64,
+			byte(value>>56), byte(value>>48), byte(value>>40), byte(value>>32),
+			byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
+	}
+}
@@ -0,0 +1,11 @@
+// Package cbor provides a compact binary encoder for soba, using CBOR (RFC 8949).
+//
+// Every entry is encoded as an indefinite-length map, so a log file is a sequence of CBOR items (RFC 8742).
+// Times, durations and binary values are written with native CBOR types: an epoch-based date/time (tag 1 or
+// tag 1001), a duration (tag 1002) and a byte string.
+//
+// A Reader converts this stream back to JSON lines, such as the ones written by the JSON encoder.
+//
+// Please be advised that it's an internal package, so expect compatibility break.
+// However, sharing is caring, you may import this package if you need a CBOR encoder.
+package cbor
@@ -0,0 +1,89 @@
+package cbor
+
+import (
+	"sync"
+
+	"github.com/novln/soba/encoder"
+)
+
+// Encoder is a CBOR encoder that isn't safe for concurrent access.
+// To encode a new instance/object, you should use Encode() method that will handles a lot of boilerplate for you.
+// Finally, when you have retrieve the buffer content, execute Close() method to recycles underlying resources.
+type Encoder struct {
+	buffer []byte
+}
+
+// Bytes return the encoder content buffer.
+func (encoder *Encoder) Bytes() []byte {
+	return encoder.buffer
+}
+
+// Close recycles underlying resources of encoder.
+func (encoder *Encoder) Close() {
+	// Proper usage of a sync.Pool requires each entry to have approximately
+	// the same memory cost. To obtain this property when the stored type
+	// contains a variably-sized buffer, we add a hard limit on the maximum buffer
+	// to place back in the pool.
+	//
+	// See https://golang.org/issue/23199
+	if encoder != nil && cap(encoder.buffer) < (1<<16) {
+		encoderPool.Put(encoder)
+	}
+}
+
+// Encode start the initialization of a new instance/object.
+// The given callback is used to provides object properties.
+// At the end of it, it will returns the encoder content buffer.
+// Since every item is self-delimited, a CBOR item isn't finished by a line break.
+func (encoder *Encoder) Encode(handler func(encoder encoder.Encoder)) []byte {
+	encoder.AppendBeginMarker()
+	handler(encoder)
+	encoder.AppendEndMarker()
+	return encoder.Bytes()
+}
+
+// FragmentType returns an identifier of the encoding used by fragments.
+func (encoder *Encoder) FragmentType() string {
+	return "cbor"
+}
+
+// EncodeFragment returns the object properties registered by given callback, as a fragment.
+func (encoder *Encoder) EncodeFragment(handler func(encoder encoder.Encoder)) []byte {
+	other := NewEncoder()
+	defer other.Close()
+
+	handler(other)
+
+	fragment := make([]byte, len(other.buffer))
+	copy(fragment, other.buffer)
+
+	return fragment
+}
+
+// AppendFragment appends given fragment in the current object.
+// Since an object is an indefinite-length map, its properties don't require any separator.
+func (encoder *Encoder) AppendFragment(fragment []byte) {
+	encoder.buffer = append(encoder.buffer, fragment...)
+}
+
+// NewEncoder creates a new CBOR Encoder.
+func NewEncoder() *Encoder {
+	entry := encoderPool.Get().(*Encoder)
+	entry.buffer = entry.buffer[:0]
+	return entry
+}
+
+// An encoder pool to reduce memory allocation pressure.
+var encoderPool = &sync.Pool{
+	New: func() interface{} {
+		return &Encoder{
+			buffer: make([]byte, 0, 1024),
+		}
+	},
+}
+
+// Ensure Encoder implements encoder.Encoder interface at compile time.
+var _ encoder.Encoder = &Encoder{}
+
+// Ensure Encoder implements encoder.FragmentEncoder interface at compile time.
+var _ encoder.FragmentEncoder = &Encoder{}
@@ -0,0 +1,114 @@
+package cbor_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/novln/soba"
+	libencoder "github.com/novln/soba/encoder"
+	"github.com/novln/soba/encoder/cbor"
+)
+
+// Global encoder for benchmark, used to avoid compiler optimization.
+var ge soba.Encoder
+
+// TestArray is a simple struct to test ArrayMarshaler interface.
+type TestArray struct {
+	Enabled bool
+	Status  string
+	ID      int64
+}
+
+func (array TestArray) Encode(encoder libencoder.ArrayEncoder) {
+	encoder.AppendBool(array.Enabled)
+	encoder.AppendString(array.Status)
+	encoder.AppendInt64(array.ID)
+}
+
+// TestObject is a simple struct to test ObjectMarshaler interface.
+type TestObject struct {
+	Enabled bool
+	Status  string
+	ID      int64
+}
+
+func (array TestObject) Encode(encoder libencoder.ObjectEncoder) {
+	encoder.AddBool("enabled", array.Enabled)
+	encoder.AddString("status", array.Status)
+	encoder.AddInt64("id", array.ID)
+}
+
+// Benchmark allocation of new CBOR Encoder.
+func BenchmarkCBOR_NewEncoder(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+
+		// Create a local logger and update it's value from to prevent the
+		// compiler to eliminate function execution.
+		var e *cbor.Encoder
+
+		for pb.Next() {
+			e = cbor.NewEncoder()
+			e.Close()
+		}
+
+		// Store logger instance in a global variable so the compiler cannot eliminate the benchmark.
+		// It create a race conditions but it's okay since it's only a benchmark and not a test.
+		ge = e
+
+	})
+}
+
+func TestCBOR_Encoder_Encode(t *testing.T) {
+	{
+		encoder := cbor.NewEncoder()
+		defer encoder.Close()
+
+		expected := []byte{0xbf, 0x66, 'f', 'o', 'o', 'b', 'a', 'r', 0x18, 0x2a, 0xff}
+
+		buffer := encoder.Encode(func(e libencoder.Encoder) {
+			e.AddInt("foobar", 42)
+		})
+
+		if !bytes.Equal(expected, buffer) {
+			t.Fatalf("Unexpected buffer: '%x' should be '%x'", buffer, expected)
+		}
+	}
+	{
+		encoder := cbor.NewEncoder()
+		defer encoder.Close()
+
+		expected := []byte{0xbf, 0x66, 's', 'h', 'a', 'r', 'e', 'd', 0xf5, 0xff}
+
+		buffer := encoder.Encode(func(e libencoder.Encoder) {
+			e.AddBool("shared", true)
+		})
+
+		if !bytes.Equal(expected, buffer) {
+			t.Fatalf("Unexpected buffer: '%x' should be '%x'", buffer, expected)
+		}
+	}
+}
+
+func TestCBOR_Encoder_Fragment(t *testing.T) {
+	encoder := cbor.NewEncoder()
+	defer encoder.Close()
+
+	fragment := encoder.EncodeFragment(func(e libencoder.Encoder) {
+		e.AddString("id", "a1")
+	})
+
+	expected := []byte{0xbf, 0x62, 'i', 'd', 0x62, 'a', '1', 0x61, 'n', 0x01, 0xff}
+
+	buffer := encoder.Encode(func(e libencoder.Encoder) {
+		encoder.AppendFragment(fragment)
+		e.AddInt("n", 1)
+	})
+
+	if !bytes.Equal(expected, buffer) {
+		t.Fatalf("Unexpected buffer: '%x' should be '%x'", buffer, expected)
+	}
+
+	if encoder.FragmentType() != soba.CBOREncoderType {
+		t.Fatalf("Unexpected fragment type: %s", encoder.FragmentType())
+	}
+}
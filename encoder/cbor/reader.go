@@ -0,0 +1,485 @@
+package cbor
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/novln/soba/encoder/json"
+)
+
+const (
+	// maxReaderDepth is the maximum number of nested arrays, maps and tags of a data item.
+	maxReaderDepth = 64
+	// maxReaderLength is the maximum length of a string or a byte string.
+	maxReaderLength = 1 << 26
+)
+
+// Reader decodes a sequence of CBOR data items, such as a log file written with a CBOR encoder, and converts
+// each of them to a JSON line.
+// Times, durations and byte strings are written as the JSON encoder does.
+type Reader struct {
+	input *bufio.Reader
+}
+
+// NewReader creates a new Reader instance from given input.
+func NewReader(input io.Reader) *Reader {
+	reader, ok := input.(*bufio.Reader)
+	if !ok {
+		reader = bufio.NewReader(input)
+	}
+	return &Reader{
+		input: reader,
+	}
+}
+
+// Next decodes the next data item and returns it as a JSON line, finished by a line break.
+// It returns io.EOF if there is no more data item.
+func (reader *Reader) Next() ([]byte, error) {
+	_, err := reader.input.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder()
+	defer encoder.Close()
+
+	err = reader.decode(encoder, 0)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode cbor data item")
+	}
+
+	encoder.AppendLineBreak()
+
+	line := make([]byte, len(encoder.Bytes()))
+	copy(line, encoder.Bytes())
+
+	return line, nil
+}
+
+// header is the header of a data item.
+type header struct {
+	major      byte
+	additional byte
+	value      uint64
+}
+
+// isBreak returns if the header is a break marker, which ends an indefinite-length item.
+func (header header) isBreak() bool {
+	return header.major == majorSimple && header.additional == additionalIndefinite
+}
+
+// isIndefinite returns if the header is the start of an indefinite-length item.
+func (header header) isIndefinite() bool {
+	return header.major != majorSimple && header.additional == additionalIndefinite
+}
+
+// readHeader reads the header of the next data item.
+func (reader *Reader) readHeader() (header, error) {
+	initial, err := reader.input.ReadByte()
+	if err != nil {
+		return header{}, err
+	}
+
+	current := header{
+		major:      initial & 0xe0,
+		additional: initial & 0x1f,
+	}
+
+	size := 0
+	switch {
+	case current.additional < additionalUint8:
+		current.value = uint64(current.additional)
+		return current, nil
+	case current.additional == additionalUint8:
+		size = 1
+	case current.additional == additionalUint16:
+		size = 2
+	case current.additional == additionalUint32:
+		size = 4
+	case current.additional == additionalUint64:
+		size = 8
+	case current.additional == additionalIndefinite:
+		switch current.major {
+		case majorBytes, majorString, majorArray, majorMap, majorSimple:
+			return current, nil
+		}
+		return header{}, errors.Errorf("unexpected indefinite length for major type %d", current.major>>5)
+	default:
+		return header{}, errors.Errorf("unexpected additional information: %d", current.additional)
+	}
+
+	for i := 0; i < size; i++ {
+		value, err := reader.input.ReadByte()
+		if err != nil {
+			return header{}, err
+		}
+		current.value = current.value<<8 | uint64(value)
+	}
+
+	return current, nil
+}
+
+// decode reads the next data item and writes it on given encoder.
+func (reader *Reader) decode(encoder *json.Encoder, depth int) error {
+	current, err := reader.readHeader()
+	if err != nil {
+		return err
+	}
+	return reader.decodeItem(encoder, current, depth)
+}
+
+// decodeItem reads the data item with given header, and writes it on given encoder.
+// nolint: gocyclo
+func (reader *Reader) decodeItem(encoder *json.Encoder, current header, depth int) error {
+	if depth > maxReaderDepth {
+		return errors.New("maximum depth exceeded")
+	}
+
+	switch current.major {
+	case majorUnsigned:
+		encoder.AppendUint64(current.value)
+		return nil
+
+	case majorNegative:
+		if current.value > math.MaxInt64 {
+			encoder.AppendFloat64(-1 - float64(current.value))
+			return nil
+		}
+		encoder.AppendInt64(-1 - int64(current.value))
+		return nil
+
+	case majorBytes:
+		value, err := reader.readBytes(current)
+		if err != nil {
+			return err
+		}
+		encoder.AppendBinary(value)
+		return nil
+
+	case majorString:
+		value, err := reader.readBytes(current)
+		if err != nil {
+			return err
+		}
+		encoder.AppendString(string(value))
+		return nil
+
+	case majorArray:
+		return reader.decodeArray(encoder, current, depth)
+
+	case majorMap:
+		return reader.decodeMap(encoder, current, depth)
+
+	case majorTag:
+		return reader.decodeTag(encoder, current, depth)
+
+	default:
+		return reader.decodeSimple(encoder, current)
+	}
+}
+
+// decodeArray reads an array with given header, and writes it on given encoder.
+func (reader *Reader) decodeArray(encoder *json.Encoder, current header, depth int) error {
+	encoder.AppendElementSeparator()
+	encoder.AppendArrayStart()
+
+	for i := uint64(0); current.isIndefinite() || i < current.value; i++ {
+		item, err := reader.readHeader()
+		if err != nil {
+			return err
+		}
+		if item.isBreak() && current.isIndefinite() {
+			break
+		}
+
+		err = reader.decodeItem(encoder, item, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	encoder.AppendArrayEnd()
+	return nil
+}
+
+// decodeMap reads a map with given header, and writes it on given encoder as an object.
+func (reader *Reader) decodeMap(encoder *json.Encoder, current header, depth int) error {
+	encoder.AppendElementSeparator()
+	encoder.AppendBeginMarker()
+
+	for i := uint64(0); current.isIndefinite() || i < current.value; i++ {
+		item, err := reader.readHeader()
+		if err != nil {
+			return err
+		}
+		if item.isBreak() && current.isIndefinite() {
+			break
+		}
+
+		key, err := reader.readKey(item)
+		if err != nil {
+			return err
+		}
+
+		encoder.AppendKey(key)
+		err = reader.decode(encoder, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	encoder.AppendEndMarker()
+	return nil
+}
+
+// decodeTag reads a tag with given header, and writes its content on given encoder.
+// Times and durations are converted, and the content of other tags is written as it is.
+func (reader *Reader) decodeTag(encoder *json.Encoder, current header, depth int) error {
+	switch current.value {
+	case tagDateTime:
+		item, err := reader.readHeader()
+		if err != nil {
+			return err
+		}
+		if item.major != majorString {
+			return reader.decodeItem(encoder, item, depth+1)
+		}
+
+		value, err := reader.readBytes(item)
+		if err != nil {
+			return err
+		}
+
+		date, err := time.Parse(time.RFC3339Nano, string(value))
+		if err != nil {
+			encoder.AppendString(string(value))
+			return nil
+		}
+
+		encoder.AppendTime(date)
+		return nil
+
+	case tagEpochDateTime, tagExtendedTime:
+		seconds, nanoseconds, err := reader.readTime()
+		if err != nil {
+			return err
+		}
+		encoder.AppendTime(time.Unix(seconds, nanoseconds).UTC())
+		return nil
+
+	case tagDuration:
+		seconds, nanoseconds, err := reader.readTime()
+		if err != nil {
+			return err
+		}
+		encoder.AppendDuration(time.Duration(seconds)*time.Second + time.Duration(nanoseconds))
+		return nil
+
+	default:
+		return reader.decode(encoder, depth+1)
+	}
+}
+
+// decodeSimple reads a simple value or a floating-point number with given header, and writes it on given encoder.
+func (reader *Reader) decodeSimple(encoder *json.Encoder, current header) error {
+	switch current.major | current.additional {
+	case simpleFalse:
+		encoder.AppendBool(false)
+	case simpleTrue:
+		encoder.AppendBool(true)
+	case simpleNull, simpleUndefined:
+		encoder.AppendNull()
+	case simpleFloat16:
+		encoder.AppendFloat32(getFloat16(uint16(current.value)))
+	case simpleFloat32:
+		encoder.AppendFloat32(math.Float32frombits(uint32(current.value)))
+	case simpleFloat64:
+		encoder.AppendFloat64(math.Float64frombits(current.value))
+	case simpleBreak:
+		return errors.New("unexpected break marker")
+	default:
+		// Unassigned simple values don't have a JSON equivalent.
+		encoder.AppendNull()
+	}
+	return nil
+}
+
+// readBytes reads the content of a string or a byte string with given header.
+func (reader *Reader) readBytes(current header) ([]byte, error) {
+	if !current.isIndefinite() {
+		if current.value > maxReaderLength {
+			return nil, errors.Errorf("maximum length exceeded: %d", current.value)
+		}
+
+		buffer := make([]byte, current.value)
+		_, err := io.ReadFull(reader.input, buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		return buffer, nil
+	}
+
+	// An indefinite-length string is a sequence of definite-length chunks of the same major type.
+	buffer := []byte{}
+	for {
+		chunk, err := reader.readHeader()
+		if err != nil {
+			return nil, err
+		}
+		if chunk.isBreak() {
+			return buffer, nil
+		}
+		if chunk.major != current.major || chunk.isIndefinite() {
+			return nil, errors.New("unexpected chunk in indefinite-length string")
+		}
+
+		value, err := reader.readBytes(chunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(buffer)+len(value) > maxReaderLength {
+			return nil, errors.Errorf("maximum length exceeded: %d", len(buffer)+len(value))
+		}
+
+		buffer = append(buffer, value...)
+	}
+}
+
+// readKey reads the key of a map with given header.
+func (reader *Reader) readKey(current header) (string, error) {
+	switch current.major {
+	case majorString:
+		value, err := reader.readBytes(current)
+		if err != nil {
+			return "", err
+		}
+		return string(value), nil
+
+	case majorUnsigned:
+		return strconv.FormatUint(current.value, 10), nil
+
+	case majorNegative:
+		if current.value > math.MaxInt64 {
+			return "", errors.New("unexpected negative integer key")
+		}
+		return strconv.FormatInt(-1-int64(current.value), 10), nil
+
+	default:
+		return "", errors.Errorf("unexpected key with major type %d", current.major>>5)
+	}
+}
+
+// readInteger reads an integer.
+func (reader *Reader) readInteger() (int64, error) {
+	current, err := reader.readHeader()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case current.major == majorUnsigned && current.value <= math.MaxInt64:
+		return int64(current.value), nil
+	case current.major == majorNegative && current.value <= math.MaxInt64:
+		return -1 - int64(current.value), nil
+	default:
+		return 0, errors.New("unexpected integer")
+	}
+}
+
+// readTime reads the content of a time or a duration: either a number of seconds, or a map with a number of
+// seconds and a fraction of second.
+// nolint: gocyclo
+func (reader *Reader) readTime() (int64, int64, error) {
+	current, err := reader.readHeader()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch {
+	case current.major == majorUnsigned && current.value <= math.MaxInt64:
+		return int64(current.value), 0, nil
+
+	case current.major == majorNegative && current.value <= math.MaxInt64:
+		return -1 - int64(current.value), 0, nil
+
+	case current.major|current.additional == simpleFloat32:
+		return getSecondsFromFloat(float64(math.Float32frombits(uint32(current.value))))
+
+	case current.major|current.additional == simpleFloat64:
+		return getSecondsFromFloat(math.Float64frombits(current.value))
+
+	case current.major == majorMap && !current.isIndefinite():
+		seconds := int64(0)
+		nanoseconds := int64(0)
+
+		for i := uint64(0); i < current.value; i++ {
+			key, err := reader.readInteger()
+			if err != nil {
+				return 0, 0, err
+			}
+			value, err := reader.readInteger()
+			if err != nil {
+				return 0, 0, err
+			}
+
+			switch key {
+			case timeKeySeconds:
+				seconds = value
+			case -3:
+				nanoseconds = value * int64(time.Millisecond)
+			case -6:
+				nanoseconds = value * int64(time.Microsecond)
+			case timeKeyNanoseconds:
+				nanoseconds = value
+			}
+		}
+
+		return seconds, nanoseconds, nil
+
+	default:
+		return 0, 0, errors.New("unexpected time")
+	}
+}
+
+// getSecondsFromFloat splits given number of seconds into seconds and nanoseconds.
+func getSecondsFromFloat(value float64) (int64, int64, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) || math.Abs(value) > math.MaxInt64/float64(time.Second) {
+		return 0, 0, errors.New("unexpected time")
+	}
+
+	seconds, fraction := math.Modf(value)
+	return int64(seconds), int64(fraction * float64(time.Second)), nil
+}
+
+// getFloat16 converts a half-precision floating-point number.
+func getFloat16(value uint16) float32 {
+	sign := uint32(value>>15) << 31
+	exponent := uint32(value>>10) & 0x1f
+	mantissa := uint32(value) & 0x3ff
+
+	switch {
+	case exponent == 0x1f:
+		// Infinity or NaN.
+		return math.Float32frombits(sign | 0xff<<23 | mantissa<<13)
+	case exponent == 0 && mantissa == 0:
+		return math.Float32frombits(sign)
+	case exponent == 0:
+		// Subnormal number.
+		result := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			return -result
+		}
+		return result
+	default:
+		return math.Float32frombits(sign | (exponent+112)<<23 | mantissa<<13)
+	}
+}
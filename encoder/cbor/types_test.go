@@ -0,0 +1,236 @@
+package cbor_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	libencoder "github.com/novln/soba/encoder"
+	"github.com/novln/soba/encoder/cbor"
+	"github.com/novln/soba/encoder/json"
+)
+
+// Test encoding of integers, with the shortest header for every value.
+func TestCBOR_Encoder_Integers(t *testing.T) {
+	scenarios := []struct {
+		input  int64
+		output []byte
+	}{
+		{0, []byte{0x00}},
+		{23, []byte{0x17}},
+		{24, []byte{0x18, 0x18}},
+		{1000, []byte{0x19, 0x03, 0xe8}},
+		{1000000, []byte{0x1a, 0x00, 0x0f, 0x42, 0x40}},
+		{1000000000000, []byte{0x1b, 0x00, 0x00, 0x00, 0xe8, 0xd4, 0xa5, 0x10, 0x00}},
+		{-1, []byte{0x20}},
+		{-100, []byte{0x38, 0x63}},
+		{-1000, []byte{0x39, 0x03, 0xe7}},
+		{math.MinInt64, []byte{0x3b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for i, scenario := range scenarios {
+		encoder := cbor.NewEncoder()
+		encoder.AppendInt64(scenario.input)
+
+		if !bytes.Equal(encoder.Bytes(), scenario.output) {
+			t.Fatalf("Unexpected result for scenario #%d: '%x' should be '%x'", i+1, encoder.Bytes(), scenario.output)
+		}
+
+		encoder.Close()
+	}
+}
+
+// Test encoding of strings, where every invalid UTF-8 byte is replaced by the Unicode replacement character.
+func TestCBOR_Encoder_Strings(t *testing.T) {
+	scenarios := []struct {
+		input  string
+		output []byte
+	}{
+		{"", []byte{0x60}},
+		{"foo", []byte{0x63, 'f', 'o', 'o'}},
+		{"\u00fc", []byte{0x62, 0xc3, 0xbc}},
+		{"\xff", []byte{0x63, 0xef, 0xbf, 0xbd}},
+		{"a\xc3b", []byte{0x65, 'a', 0xef, 0xbf, 0xbd, 'b'}},
+		{"\xed\xa0\x80", []byte{0x69, 0xef, 0xbf, 0xbd, 0xef, 0xbf, 0xbd, 0xef, 0xbf, 0xbd}},
+	}
+
+	for i, scenario := range scenarios {
+		encoder := cbor.NewEncoder()
+		encoder.AppendString(scenario.input)
+
+		if !bytes.Equal(encoder.Bytes(), scenario.output) {
+			t.Fatalf("Unexpected result for scenario #%d: '%x' should be '%x'", i+1, encoder.Bytes(), scenario.output)
+		}
+
+		encoder.Close()
+	}
+
+	encoder := cbor.NewEncoder()
+	defer encoder.Close()
+
+	encoder.AppendString("invalid \xff byte")
+
+	reader := cbor.NewReader(bytes.NewReader(encoder.Bytes()))
+	line, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	expected := "\"invalid \ufffd byte\"\n"
+	if string(line) != expected {
+		t.Fatalf("Unexpected result: '%s' should be '%s'", string(line), expected)
+	}
+}
+
+// Test that an object encoded with a CBOR encoder is read as it would have been encoded with a JSON encoder.
+// nolint: gocyclo
+func TestCBOR_Reader_Compatibility(t *testing.T) {
+	date := time.Date(2019, 4, 20, 9, 53, 13, 0, time.UTC)
+	handler := func(encoder libencoder.Encoder) {
+		encoder.AddArray("array", TestArray{Enabled: true, Status: "ok", ID: 42})
+		encoder.AddObject("object", TestObject{Enabled: false, Status: "ko", ID: -42})
+		encoder.AddObjects("objects", []libencoder.ObjectMarshaler{
+			TestObject{ID: 1}, TestObject{ID: 2},
+		})
+		encoder.AddInt("int", -7)
+		encoder.AddInts("ints", []int{1, -2, 3000000})
+		encoder.AddInt8s("int8s", []int8{math.MinInt8, math.MaxInt8})
+		encoder.AddInt64("int64", math.MinInt64)
+		encoder.AddUint64("uint64", math.MaxUint64)
+		encoder.AddUint8s("uint8s", []uint8{0, 255})
+		encoder.AddFloat32("float32", 3.25)
+		encoder.AddFloat64s("float64s", []float64{-0.5, math.NaN(), math.Inf(1), math.Inf(-1)})
+		encoder.AddString("string", "I ❤️ go!\n\"quoted\"")
+		encoder.AddStrings("strings", []string{"", "foo"})
+		encoder.AddStringer("stringer", time.Second)
+		encoder.AddTime("time", date)
+		encoder.AddTimes("times", []time.Time{date.Add(123456789 * time.Nanosecond), date.Add(-time.Hour)})
+		encoder.AddDuration("duration", 90*time.Second)
+		encoder.AddDurations("durations", []time.Duration{
+			1500 * time.Millisecond, -2*time.Second - 250*time.Microsecond, 0,
+		})
+		encoder.AddBool("bool", true)
+		encoder.AddBools("bools", []bool{false, true})
+		encoder.AddBinary("binary", []byte("Hello world!"))
+		encoder.AddBinary("empty", []byte{})
+		encoder.AddNull("null")
+		encoder.AddArray("nested", TestNestedArray{})
+		encoder.AddArray("empties", TestEmptyArray{})
+	}
+
+	expected := json.NewEncoder()
+	defer expected.Close()
+	expected.Encode(handler)
+
+	encoder := cbor.NewEncoder()
+	defer encoder.Close()
+	encoder.Encode(handler)
+
+	reader := cbor.NewReader(bytes.NewReader(encoder.Bytes()))
+	line, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if !bytes.Equal(line, expected.Bytes()) {
+		t.Fatalf("Unexpected line: '%s' should be '%s'", string(line), string(expected.Bytes()))
+	}
+
+	_, err = reader.Next()
+	if err != io.EOF {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if len(encoder.Bytes()) >= len(expected.Bytes()) {
+		t.Fatalf("Unexpected size: %d should be lower than %d", len(encoder.Bytes()), len(expected.Bytes()))
+	}
+}
+
+// TestNestedArray is an array with nested arrays and objects.
+type TestNestedArray struct{}
+
+func (TestNestedArray) Encode(encoder libencoder.ArrayEncoder) {
+	encoder.AppendArray(TestArray{ID: 1})
+	encoder.AppendObject(TestObject{ID: 2})
+	encoder.AppendArray(TestEmptyArray{})
+	encoder.AppendNull()
+}
+
+// TestEmptyArray is an array without any element.
+type TestEmptyArray struct{}
+
+func (TestEmptyArray) Encode(encoder libencoder.ArrayEncoder) {}
+
+// Test decoding of a stream with data items written by other CBOR encoders, from RFC 8949 examples.
+func TestCBOR_Reader_Items(t *testing.T) {
+	scenarios := []struct {
+		input  []byte
+		output string
+	}{
+		{[]byte{0xf9, 0x3c, 0x00}, "1\n"},
+		{[]byte{0xf9, 0xc4, 0x00}, "-4\n"},
+		{[]byte{0xf9, 0x7c, 0x00}, "\"+Inf\"\n"},
+		{[]byte{0xf9, 0x00, 0x01}, "0.000000059604645\n"},
+		{[]byte{0x3b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "-18446744073709552000\n"},
+		{[]byte{0xf7}, "null\n"},
+		{[]byte{0xf0}, "null\n"},
+		{[]byte{0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0}, "\"2013-03-21T20:04:00Z\"\n"},
+		{[]byte{0xc1, 0xfb, 0x41, 0xd4, 0x52, 0xd9, 0xec, 0x20, 0x00, 0x00}, "\"2013-03-21T20:04:00.5Z\"\n"},
+		{
+			append([]byte{0xc0, 0x74}, "2013-03-21T20:04:00Z"...),
+			"\"2013-03-21T20:04:00Z\"\n",
+		},
+		{[]byte{0xc0, 0x63, 'f', 'o', 'o'}, "\"foo\"\n"},
+		{[]byte{0xd9, 0x03, 0xea, 0xa2, 0x01, 0x01, 0x22, 0x19, 0x01, 0xf4}, "\"1.5s\"\n"},
+		{[]byte{0xd9, 0xd9, 0xf7, 0xa1, 0x61, 'a', 0x01}, "{\"a\":1}\n"},
+		{[]byte{0xa2, 0x01, 0x02, 0x20, 0x80}, "{\"1\":2,\"-1\":[]}\n"},
+		{[]byte{0x83, 0x01, 0x82, 0x02, 0x03, 0xa0}, "[1,[2,3],{}]\n"},
+		{[]byte{0x7f, 0x62, 'f', 'o', 0x61, 'o', 0xff}, "\"foo\"\n"},
+		{[]byte{0x5f, 0x41, 0x01, 0x42, 0x02, 0x03, 0xff}, "\"AQID\"\n"},
+		{[]byte{0xc2, 0x41, 0x01}, "\"AQ==\"\n"},
+	}
+
+	for i, scenario := range scenarios {
+		reader := cbor.NewReader(bytes.NewReader(scenario.input))
+		line, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Unexpected error for scenario #%d: %+v", i+1, err)
+		}
+		if string(line) != scenario.output {
+			t.Fatalf("Unexpected result for scenario #%d: '%s' should be '%s'", i+1, string(line), scenario.output)
+		}
+	}
+}
+
+// Test decoding of a stream with invalid data items.
+func TestCBOR_Reader_Invalid(t *testing.T) {
+	scenarios := [][]byte{
+		{0xbf, 0x61, 'a'},
+		{0xbf, 0x61},
+		{0x19, 0x01},
+		{0x1c},
+		{0x3f},
+		{0xff},
+		{0xa1, 0xf5, 0x01},
+		{0x7f, 0x41, 0x01, 0xff},
+		{0x7f, 0x7f, 0xff, 0xff},
+		{0xc1, 0x61, 'a'},
+		{0xc1, 0xfb, 0x7f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		{0xd9, 0x03, 0xea, 0xa1, 0x61, 'a', 0x01},
+		{0x7b, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff},
+		bytes.Repeat([]byte{0x81}, 100),
+	}
+
+	for i, scenario := range scenarios {
+		reader := cbor.NewReader(bytes.NewReader(scenario))
+		line, err := reader.Next()
+		if err == nil {
+			t.Fatalf("An error was expected for scenario #%d: %s", i+1, string(line))
+		}
+		if errors.Is(err, io.EOF) {
+			t.Fatalf("Unexpected end of stream for scenario #%d", i+1)
+		}
+	}
+}
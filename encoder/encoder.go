@@ -24,6 +24,18 @@ type Encoder interface {
 	Encode(handler func(encoder Encoder)) []byte
 }
 
+// FragmentEncoder is an Encoder that can reuse pre-encoded object properties, called fragments.
+// A fragment is only valid for encoders with the same fragment type.
+type FragmentEncoder interface {
+	Encoder
+	// FragmentType returns an identifier of the encoding used by fragments.
+	FragmentType() string
+	// EncodeFragment returns the object properties registered by given callback, as a fragment.
+	EncodeFragment(handler func(encoder Encoder)) []byte
+	// AppendFragment appends given fragment in the current object.
+	AppendFragment(fragment []byte)
+}
+
 // ArrayEncoder is a strongly-typed, encoding-agnostic interface for adding array to the logging context.
 // Also, be advised that Encoder aren't safe for concurrent use.
 type ArrayEncoder interface {
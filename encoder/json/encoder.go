@@ -44,6 +44,33 @@ func (encoder *Encoder) Encode(handler func(encoder encoder.Encoder)) []byte {
 	return encoder.Bytes()
 }
 
+// FragmentType returns an identifier of the encoding used by fragments.
+func (encoder *Encoder) FragmentType() string {
+	return "json"
+}
+
+// EncodeFragment returns the object properties registered by given callback, as a fragment.
+func (encoder *Encoder) EncodeFragment(handler func(encoder encoder.Encoder)) []byte {
+	other := NewEncoder()
+	defer other.Close()
+
+	handler(other)
+
+	fragment := make([]byte, len(other.buffer))
+	copy(fragment, other.buffer)
+
+	return fragment
+}
+
+// AppendFragment appends given fragment in the current object.
+func (encoder *Encoder) AppendFragment(fragment []byte) {
+	if len(fragment) == 0 {
+		return
+	}
+	encoder.AppendElementSeparator()
+	encoder.buffer = append(encoder.buffer, fragment...)
+}
+
 // NewEncoder creates a new JSON Encoder.
 func NewEncoder() *Encoder {
 	entry := encoderPool.Get().(*Encoder)
@@ -62,3 +89,6 @@ var encoderPool = &sync.Pool{
 
 // Ensure Encoder implements encoder.Encoder interface at compile time.
 var _ encoder.Encoder = &Encoder{}
+
+// Ensure Encoder implements encoder.FragmentEncoder interface at compile time.
+var _ encoder.FragmentEncoder = &Encoder{}
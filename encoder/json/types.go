@@ -418,7 +418,8 @@ func (encoder *Encoder) AppendBool(value bool) {
 func (encoder *Encoder) AppendTime(value time.Time) {
 	encoder.AppendElementSeparator()
 	encoder.buffer = append(encoder.buffer, '"')
-	encoder.safeAddByteString(value.AppendFormat(nil, time.RFC3339Nano))
+	// A RFC3339 timestamp doesn't require any escaping.
+	encoder.buffer = value.AppendFormat(encoder.buffer, time.RFC3339Nano)
 	encoder.buffer = append(encoder.buffer, '"')
 }
 
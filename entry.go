@@ -18,12 +18,32 @@ const (
 
 // Entry represents a log event.
 type Entry struct {
-	name    string
-	unix    int64
-	level   Level
-	message string
-	fields  []Field
-	indexes map[string]int
+	name     string
+	unix     int64
+	level    Level
+	message  string
+	fields   fieldSet
+	redactor *Redactor
+	// context contains the fields of the logger, if they are written using a pre-encoded fragment.
+	context *fieldContext
+	// all is a buffer used to return the fields of the context with the fields of the entry.
+	all []Field
+	// encoded contains the entry encoded by every type of encoder requested by the appenders.
+	encoded []encodedEntry
+	// schema is the schema used by the encoder currently writing the entry.
+	schema *Schema
+	// write is the callback given to the encoder, created once for every pooled entry to avoid an allocation.
+	write func(encoder Encoder)
+	// redact is the encoder that applies the redactor rules, reused for every field of the entry.
+	redact redactEncoder
+}
+
+// encodedEntry is an entry encoded with a type of encoder.
+type encodedEntry struct {
+	kind    string
+	schema  *Schema
+	encoder Encoder
+	buffer  []byte
 }
 
 // Name returns entry name.
@@ -46,68 +66,177 @@ func (entry Entry) Unix() int64 {
 	return entry.unix
 }
 
-// Fields returns entry fields.
+// Fields returns entry fields. Its lazy fields are computed, once.
 func (entry Entry) Fields() []Field {
-	return entry.fields
+	entry.fields.resolve()
+
+	if entry.context == nil {
+		return entry.fields.list
+	}
+
+	list := append(entry.all[:0], entry.context.list()...)
+	list = append(list, entry.fields.list...)
+
+	return list
 }
 
 // Flush recycles entry.
 func (entry *Entry) Flush() {
 	if entry != nil {
-		entry.fields = entry.fields[:0]
-		for name := range entry.indexes {
-			delete(entry.indexes, name)
+		entry.fields.reset()
+		entry.redactor = nil
+		entry.context = nil
+		entry.schema = nil
+		entry.redact = redactEncoder{}
+		for i := range entry.encoded {
+			entry.encoded[i].encoder.Close()
+			entry.encoded[i] = encodedEntry{}
 		}
+		entry.encoded = entry.encoded[:0]
 		entryPool.Put(entry)
 	}
 }
 
 // NewEntry creates a new entry with given configuration.
 // In case of field with duplicate name, the last one will be kept.
+// Also, a namespace field will nest every following fields, and the duplicate detection will be scoped
+// to this namespace.
 func NewEntry(name string, level Level, message string, fields ...[]Field) *Entry {
+	return newEntry(name, time.Now().Unix(), level, message, keyFormatter{}, nil, fields)
+}
+
+// newEntry creates a new entry with given configuration and timestamp, as a number of seconds since the Unix epoch,
+// normalizing field keys with given key formatter.
+// If a redactor is given, it will be applied when the entry is written.
+func newEntry(name string, unix int64, level Level, message string, keys keyFormatter, redactor *Redactor,
+	fields [][]Field) *Entry {
+
 	entry := entryPool.Get().(*Entry)
 	entry.name = name
 	entry.level = level
 	entry.message = message
-	entry.unix = time.Now().Unix()
+	entry.unix = unix
+	entry.fields.keys = keys
+	entry.redactor = redactor
 
+	set := &entry.fields
 	for x := range fields {
 		for y := range fields[x] {
-			// Avoid duplication of field with the same name.
-			// Last field overwrite the previous one.
-			name := fields[x][y].name
-			i, ok := entry.indexes[name]
-			if !ok {
-				entry.indexes[name] = len(entry.fields)
-				entry.fields = append(entry.fields, fields[x][y])
-			} else {
-				entry.fields[i] = fields[x][y]
-			}
+			set = set.add(fields[x][y])
 		}
 	}
 
 	return entry
 }
 
-// WriteEntry writes entry informations on the given encoder.
-func WriteEntry(entry *Entry, encoder Encoder) []byte {
-	return encoder.Encode(func(encoder Encoder) {
-		encoder.AddString(LoggerKey, entry.name)
-		encoder.AddTime(TimeKey, time.Unix(entry.unix, 0).UTC())
-		encoder.AddStringer(LevelKey, entry.level)
-		encoder.AddString(MessageKey, entry.message)
-		for _, field := range entry.fields {
-			field.Write(encoder)
+// Encode returns the entry encoded with given type of encoder, such as "json", using the default schema.
+// The entry is encoded once for every type of encoder, so appenders with the same encoder share the same buffer:
+// it must not be modified, and it's only valid until the entry is recycled.
+// An unknown type of encoder is considered as JSONEncoderType.
+func (entry *Entry) Encode(kind string) []byte {
+	return entry.EncodeWithSchema(kind, DefaultSchema)
+}
+
+// EncodeWithSchema returns the entry encoded with given type of encoder and given schema.
+// Like Encode, the entry is encoded once for every type of encoder and schema.
+// A nil schema is considered as DefaultSchema.
+func (entry *Entry) EncodeWithSchema(kind string, schema *Schema) []byte {
+	if !IsEncoderTypeValid(kind) || kind == "" {
+		kind = JSONEncoderType
+	}
+	if schema == nil {
+		schema = DefaultSchema
+	}
+
+	for i := range entry.encoded {
+		if entry.encoded[i].kind == kind && entry.encoded[i].schema == schema {
+			return entry.encoded[i].buffer
 		}
+	}
+
+	encoder, _ := NewEncoder(kind)
+	buffer := WriteEntryWithSchema(entry, encoder, schema)
+	entry.encoded = append(entry.encoded, encodedEntry{
+		kind:    kind,
+		schema:  schema,
+		encoder: encoder,
+		buffer:  buffer,
 	})
+
+	return buffer
+}
+
+// Redactor returns the redactor applied on entry, if any.
+func (entry Entry) Redactor() *Redactor {
+	return entry.redactor
+}
+
+// WriteEntry writes entry informations on the given encoder, using the default schema.
+// If the entry has a redactor, it's applied on the message and the fields.
+func WriteEntry(entry *Entry, encoder Encoder) []byte {
+	return WriteEntryWithSchema(entry, encoder, DefaultSchema)
+}
+
+// WriteEntryWithSchema writes entry informations on the given encoder, using given schema for the key names and
+// the layout of the built-in fields.
+// If the entry has a redactor, it's applied on the message and the fields.
+func WriteEntryWithSchema(entry *Entry, encoder Encoder, schema *Schema) []byte {
+	entry.schema = schema
+	return encoder.Encode(entry.write)
+}
+
+// writeEntry writes entry informations on the given encoder, using given schema.
+func writeEntry(entry *Entry, encoder Encoder, schema *Schema) {
+	entry.fields.resolve()
+	if schema.LoggerKey != "" {
+		encoder.AddString(schema.LoggerKey, entry.name)
+	}
+	schema.writeTime(encoder, entry.unix)
+	schema.writeLevel(encoder, entry.level)
+	encoder.AddString(schema.MessageKey, entry.redactor.String(entry.message))
+	for _, field := range schema.Fields {
+		field.Write(encoder)
+	}
+	writeContext(entry, encoder, schema)
+	fields := entry.redactor.reusedEncoder(&entry.redact, encoder, schema.FieldPrefix)
+	for _, field := range entry.fields.list {
+		schema.writeField(entry.redactor.fieldEncoder(fields, encoder, field), field, entry.fields.keys.protect)
+	}
+}
+
+// writeContext writes the fields of the logger, if any, on the given encoder.
+// It uses a pre-encoded fragment if the encoder supports it.
+func writeContext(entry *Entry, encoder Encoder, schema *Schema) {
+	if entry.context == nil {
+		return
+	}
+
+	fragments, ok := encoder.(FragmentEncoder)
+	if ok {
+		fragments.AppendFragment(entry.context.fragment(fragments, schema))
+		return
+	}
+
+	fields := entry.redactor.reusedEncoder(&entry.redact, encoder, schema.FieldPrefix)
+	for _, field := range entry.context.list() {
+		schema.writeField(entry.redactor.fieldEncoder(fields, encoder, field), field, entry.context.keys.protect)
+	}
 }
 
 // An entry pool to reduce memory allocation pressure.
 var entryPool = &sync.Pool{
 	New: func() interface{} {
-		return &Entry{
-			indexes: make(map[string]int, 64),
-			fields:  make([]Field, 0, 64),
+		entry := &Entry{
+			fields: fieldSet{
+				list:    make([]Field, 0, 64),
+				indexes: make(map[string]int, 64),
+			},
+			all:     make([]Field, 0, 64),
+			encoded: make([]encodedEntry, 0, 4),
+		}
+		entry.write = func(encoder Encoder) {
+			writeEntry(entry, encoder, entry.schema)
 		}
+		return entry
 	},
 }
@@ -1,6 +1,7 @@
 package soba_test
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	random "github.com/Pallinder/go-randomdata"
 
 	"github.com/novln/soba"
+	"github.com/novln/soba/encoder/cbor"
 	"github.com/novln/soba/encoder/json"
 )
 
@@ -209,6 +211,40 @@ func TestEntry_DuplicateFieldName(t *testing.T) {
 	}
 }
 
+// Test entry in case of a duplicate name in namespaced fields.
+func TestEntry_DuplicateNamespacedFieldName(t *testing.T) {
+	name := "prism"
+	message := "Lorem ipsum dolor sit amet, consectetur adipiscing elit."
+	entry := soba.NewEntry(name, soba.InfoLevel, message,
+		[]soba.Field{
+			soba.Int("silver", 1),
+			soba.Namespace("db"),
+			soba.Int("silver", 2),
+			soba.Int("chrome", 3),
+		},
+		[]soba.Field{
+			soba.Int("chrome", 4),
+			soba.Namespace("query"),
+			soba.Int("silver", 5),
+		},
+	)
+	defer entry.Flush()
+
+	if len(entry.Fields()) != 2 {
+		t.Fatalf(`Unexpected result for "fields": it should have "%d" fields, not "%d"`, 2, len(entry.Fields()))
+	}
+	f0 := DebugField(entry.Fields()[0])
+	if f0 != `"silver":1` {
+		t.Fatalf(`Unexpected result for "fields" at position 0: it should be "%s", not "%s"`,
+			`"silver":1`, f0)
+	}
+	f1 := DebugField(entry.Fields()[1])
+	if f1 != `"db":{"silver":2,"chrome":4,"query":{"silver":5}}` {
+		t.Fatalf(`Unexpected result for "fields" at position 1: it should be "%s", not "%s"`,
+			`"db":{"silver":2,"chrome":4,"query":{"silver":5}}`, f1)
+	}
+}
+
 // Test entry in case of a duplicate name in fields.
 func TestEntry_WriteEntry(t *testing.T) {
 	encoder := json.NewEncoder()
@@ -237,3 +273,66 @@ func TestEntry_WriteEntry(t *testing.T) {
 		t.Fatalf("Unexpected entry line: %s", line)
 	}
 }
+
+// Test entry encoded once for every type of encoder.
+func TestEntry_Encode(t *testing.T) {
+	entry := soba.NewEntry("test", soba.InfoLevel, "A log message", []soba.Field{
+		soba.Bool("test", true),
+	})
+	defer entry.Flush()
+
+	buffer1 := entry.Encode(soba.JSONEncoderType)
+	buffer2 := entry.Encode(soba.JSONEncoderType)
+	buffer3 := entry.Encode("")
+
+	if &buffer1[0] != &buffer2[0] || &buffer1[0] != &buffer3[0] {
+		t.Fatal("Entry should be encoded once for every type of encoder")
+	}
+
+	suffix := `","level":"info","message":"A log message","test":true}` + "\n"
+	if !strings.HasSuffix(string(buffer1), suffix) {
+		t.Fatalf("Unexpected entry line: %s", string(buffer1))
+	}
+}
+
+// Test entry encoded with a CBOR encoder, alongside a JSON encoder.
+func TestEntry_EncodeCBOR(t *testing.T) {
+	appender := &EncoderAppender{}
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender})
+	logger = logger.With(soba.String("request_id", "a1b2c3"))
+	logger.Info("Payload received", soba.Binary("payload", []byte("soba")), soba.Duration("elapsed", time.Second))
+
+	if len(appender.lines) != 1 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", len(appender.lines), 1)
+	}
+
+	line, err := cbor.NewReader(bytes.NewReader(appender.lines[0])).Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if string(line) != appender.expected[0] {
+		t.Fatalf("Unexpected entry line: '%s' should be '%s'", string(line), appender.expected[0])
+	}
+}
+
+// EncoderAppender is an appender that keeps the entries encoded with a CBOR encoder and a JSON encoder.
+type EncoderAppender struct {
+	lines    [][]byte
+	expected []string
+}
+
+func (EncoderAppender) Name() string {
+	return "encoder"
+}
+
+func (EncoderAppender) Close() error {
+	return nil
+}
+
+func (appender *EncoderAppender) Write(entry *soba.Entry) {
+	buffer := entry.Encode(soba.CBOREncoderType)
+	appender.lines = append(appender.lines, append([]byte{}, buffer...))
+	appender.expected = append(appender.expected, string(entry.Encode(soba.JSONEncoderType)))
+}
@@ -0,0 +1,270 @@
+package soba
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnvConfigPrefix defines the prefix of environment variables that override a configuration.
+//
+// An environment variable overrides a property of either the root logger, a logger or an appender:
+//
+//	SOBA_ROOT_LEVEL=debug
+//	SOBA_ROOT_APPENDERS=stdout,requests
+//	SOBA_LOGGERS_APP_BACKEND_DB_LEVEL=info
+//	SOBA_LOGGERS_APP_REQUESTS_ADDITIVE=false
+//	SOBA_APPENDERS_REQUESTS_PATH=/var/log/requests.log
+//	SOBA_APPENDERS_REQUESTS_MAX_BYTES=10485760
+//
+// A logger or appender name is written in upper case, with its "." and "-" replaced by "_".
+// If no logger or appender of the configuration matches this name, a new one is declared, whose name is written
+// in lower case with its "_" replaced by ".".
+//
+// A logger accepts LEVEL, APPENDERS and ADDITIVE properties, and an appender accepts TYPE, PATH, MAX_BYTES,
+// BACKUP, ENCODER and SCHEMA properties. A list is separated by ",".
+const EnvConfigPrefix = "SOBA_"
+
+const (
+	envRootPrefix      = EnvConfigPrefix + "ROOT_"
+	envLoggersPrefix   = EnvConfigPrefix + "LOGGERS_"
+	envAppendersPrefix = EnvConfigPrefix + "APPENDERS_"
+)
+
+// envLoggerProperties is the list of properties of a logger that could be overridden.
+var envLoggerProperties = []string{
+	"LEVEL",
+	"APPENDERS",
+	"ADDITIVE",
+}
+
+// envAppenderProperties is the list of properties of an appender that could be overridden.
+var envAppenderProperties = []string{
+	"MAX_BYTES",
+	"TYPE",
+	"PATH",
+	"BACKUP",
+	"ENCODER",
+	"SCHEMA",
+}
+
+// ApplyEnvConfig overrides given configuration with the environment variables prefixed by EnvConfigPrefix.
+// It's executed by ParseConfig, and by Load and Create with the default configuration.
+func ApplyEnvConfig(conf *Config) error {
+	return applyEnvConfig(conf, os.Environ())
+}
+
+// applyEnvConfig overrides given configuration with given environment, using the "key=value" format.
+func applyEnvConfig(conf *Config, environ []string) error {
+	if conf == nil {
+		return errors.Errorf("given configuration is empty")
+	}
+
+	for _, variable := range environ {
+		key, value := splitEnvVariable(variable)
+
+		var err error
+		switch {
+		case strings.HasPrefix(key, envRootPrefix):
+			property := strings.TrimPrefix(key, envRootPrefix)
+			err = applyEnvLoggerProperty(&conf.Root, property, value)
+			setEnvPosition(conf, joinPath("root", strings.ToLower(property)), key)
+
+		case strings.HasPrefix(key, envLoggersPrefix):
+			err = applyEnvLoggerConfig(conf, strings.TrimPrefix(key, envLoggersPrefix), value)
+
+		case strings.HasPrefix(key, envAppendersPrefix):
+			err = applyEnvAppenderConfig(conf, strings.TrimPrefix(key, envAppendersPrefix), value)
+
+		default:
+			continue
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "cannot apply environment variable %s", key)
+		}
+
+		// Configuration has been mutated, so it should be verified again.
+		conf.verified = false
+	}
+
+	return nil
+}
+
+func applyEnvLoggerConfig(conf *Config, key string, value string) error {
+	name, property, ok := splitEnvKey(key, envLoggerProperties)
+	if !ok {
+		return errors.Errorf("unknown logger property: %s", key)
+	}
+
+	if conf.Loggers == nil {
+		conf.Loggers = map[string]ConfigLogger{}
+	}
+
+	name = lookupEnvName(name, loggerNames(conf))
+	logger := conf.Loggers[name]
+
+	err := applyEnvLoggerProperty(&logger, property, value)
+	if err != nil {
+		return err
+	}
+
+	conf.Loggers[name] = logger
+	setEnvPosition(conf, joinPath(joinPath("loggers", name), strings.ToLower(property)), envLoggersPrefix+key)
+
+	return nil
+}
+
+func applyEnvLoggerProperty(logger *ConfigLogger, property string, value string) error {
+	switch property {
+	case "LEVEL":
+		logger.Level = value
+
+	case "APPENDERS":
+		logger.Appenders = splitEnvList(value)
+
+	case "ADDITIVE":
+		additive, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.Errorf("additive is invalid: %s", value)
+		}
+		logger.Additive = additive
+
+	default:
+		return errors.Errorf("unknown logger property: %s", property)
+	}
+
+	return nil
+}
+
+func applyEnvAppenderConfig(conf *Config, key string, value string) error {
+	name, property, ok := splitEnvKey(key, envAppenderProperties)
+	if !ok {
+		return errors.Errorf("unknown appender property: %s", key)
+	}
+
+	if conf.Appenders == nil {
+		conf.Appenders = map[string]ConfigAppender{}
+	}
+
+	name = lookupEnvName(name, appenderNames(conf))
+	appender := conf.Appenders[name]
+
+	switch property {
+	case "TYPE":
+		appender.Type = value
+
+	case "PATH":
+		appender.Path = value
+
+	case "MAX_BYTES":
+		size, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.Errorf("max bytes is invalid: %s", value)
+		}
+		appender.MaxBytes = size
+
+	case "BACKUP":
+		backup, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.Errorf("backup is invalid: %s", value)
+		}
+		appender.Backup = backup
+
+	case "ENCODER":
+		appender.Encoder = value
+
+	case "SCHEMA":
+		appender.Schema = ConfigSchema{Preset: value}
+	}
+
+	conf.Appenders[name] = appender
+	setEnvPosition(conf, joinPath(joinPath("appenders", name), strings.ToLower(property)), envAppendersPrefix+key)
+
+	return nil
+}
+
+// setEnvPosition locates given key path and its children with given environment variable, since its value is now
+// defined by this variable rather than the configuration file.
+func setEnvPosition(conf *Config, path string, key string) {
+	if conf.positions == nil {
+		conf.positions = map[string]position{}
+	}
+
+	for name := range conf.positions {
+		if strings.HasPrefix(name, path+".") || strings.HasPrefix(name, path+"[") {
+			delete(conf.positions, name)
+		}
+	}
+
+	conf.positions[path] = position{file: "$" + key}
+}
+
+// splitEnvVariable returns the key and the value of an environment variable.
+func splitEnvVariable(variable string) (string, string) {
+	i := strings.Index(variable, "=")
+	if i < 0 {
+		return variable, ""
+	}
+	return variable[:i], variable[i+1:]
+}
+
+// splitEnvKey returns the name and the property of given key, such as "APP_BACKEND_DB" and "LEVEL" for
+// "APP_BACKEND_DB_LEVEL".
+func splitEnvKey(key string, properties []string) (string, string, bool) {
+	for _, property := range properties {
+		suffix := "_" + property
+		if strings.HasSuffix(key, suffix) && len(key) > len(suffix) {
+			return strings.TrimSuffix(key, suffix), property, true
+		}
+	}
+	return "", "", false
+}
+
+// splitEnvList returns the elements of a list separated by ",".
+func splitEnvList(value string) []string {
+	list := []string{}
+	for _, element := range strings.Split(value, ",") {
+		element = strings.TrimSpace(element)
+		if element != "" {
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+// lookupEnvName returns the first name in given list that matches given environment name, or a new name otherwise.
+func lookupEnvName(name string, names []string) string {
+	for i := range names {
+		if toEnvName(names[i]) == name {
+			return names[i]
+		}
+	}
+	return strings.ToLower(strings.Replace(name, "_", ".", -1))
+}
+
+// toEnvName converts a logger or appender name to its environment format.
+func toEnvName(name string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+func loggerNames(conf *Config) []string {
+	names := make([]string, 0, len(conf.Loggers))
+	for name := range conf.Loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func appenderNames(conf *Config) []string {
+	names := make([]string, 0, len(conf.Appenders))
+	for name := range conf.Appenders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,114 @@
+package soba_test
+
+import (
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+// Test configuration overridden by environment variables.
+// nolint: gocyclo
+func TestEnv_ParseConfig(t *testing.T) {
+	t.Setenv("SOBA_ROOT_LEVEL", "debug")
+	t.Setenv("SOBA_ROOT_APPENDERS", "stdout, requests")
+	t.Setenv("SOBA_LOGGERS_APP_BACKEND_DB_LEVEL", "error")
+	t.Setenv("SOBA_LOGGERS_APP_REQUESTS_ADDITIVE", "true")
+	t.Setenv("SOBA_LOGGERS_APP_WORKER_LEVEL", "warn")
+	t.Setenv("SOBA_LOGGERS_APP_WORKER_APPENDERS", "requests")
+	t.Setenv("SOBA_APPENDERS_REQUESTS_PATH", "testdata/logs/env.log")
+	t.Setenv("SOBA_APPENDERS_REQUESTS_MAX_BYTES", "1024")
+	t.Setenv("SOBA_APPENDERS_REQUESTS_SCHEMA", "ecs")
+
+	for _, path := range []string{"testdata/simple.yaml", "testdata/simple.json", "testdata/simple.toml"} {
+		conf, err := soba.ParseConfig(path)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %+v", path, err)
+		}
+
+		if conf.Root.Level != "debug" || len(conf.Root.Appenders) != 2 || conf.Root.Appenders[1] != "requests" {
+			t.Fatalf("Unexpected root logger for %s: %+v", path, conf.Root)
+		}
+		if conf.Loggers["app.backend.db"].Level != "error" {
+			t.Fatalf("Unexpected logger for %s: %+v", path, conf.Loggers["app.backend.db"])
+		}
+		if !conf.Loggers["app.requests"].Additive || conf.Loggers["app.requests"].Level != "info" {
+			t.Fatalf("Unexpected logger for %s: %+v", path, conf.Loggers["app.requests"])
+		}
+		if conf.Loggers["app.worker"].Level != "warn" || len(conf.Loggers["app.worker"].Appenders) != 1 {
+			t.Fatalf("Unexpected logger for %s: %+v", path, conf.Loggers["app.worker"])
+		}
+
+		appender := conf.Appenders["requests"]
+		if appender.Type != soba.FileAppenderType || appender.Path != "testdata/logs/env.log" ||
+			appender.MaxBytes != 1024 || appender.Schema.Preset != "ecs" {
+			t.Fatalf("Unexpected appender for %s: %+v", path, appender)
+		}
+	}
+}
+
+// Test invalid environment variables.
+func TestEnv_ParseConfigInvalid(t *testing.T) {
+	scenarios := []struct {
+		key   string
+		value string
+	}{
+		{key: "SOBA_ROOT_COLOR", value: "blue"},
+		{key: "SOBA_ROOT_LEVEL", value: "trace"},
+		{key: "SOBA_LOGGERS_APP_BACKEND_DB_COLOR", value: "blue"},
+		{key: "SOBA_LOGGERS_LEVEL", value: "info"},
+		{key: "SOBA_LOGGERS_APP_REQUESTS_ADDITIVE", value: "maybe"},
+		{key: "SOBA_APPENDERS_REQUESTS_MAX_BYTES", value: "1kb"},
+		{key: "SOBA_APPENDERS_REQUESTS_BACKUP", value: "maybe"},
+		{key: "SOBA_APPENDERS_REQUESTS_COLOR", value: "blue"},
+		{key: "SOBA_APPENDERS_EVENTS_TYPE", value: "kafka"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.key, func(t *testing.T) {
+			t.Setenv(scenario.key, scenario.value)
+
+			_, err := soba.ParseConfig("testdata/simple.yaml")
+			if err == nil {
+				t.Fatalf("An error was expected for %s=%s", scenario.key, scenario.value)
+			}
+		})
+	}
+}
+
+// Test default configuration overridden by environment variables.
+func TestEnv_Create(t *testing.T) {
+	t.Setenv(soba.EnvConfigPath, "")
+	t.Setenv("SOBA_ROOT_LEVEL", "error")
+
+	handler, err := soba.Create()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	defer func() {
+		err := handler.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}()
+
+	logger := handler.New("foobar")
+	if logger.Level() != soba.ErrorLevel {
+		t.Fatalf("Unexpected level: %s should be %s", logger.Level(), soba.ErrorLevel)
+	}
+
+	conf := soba.NewDefaultConfig()
+	err = soba.ApplyEnvConfig(conf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if conf.Root.Level != "error" {
+		t.Fatalf("Unexpected level: %s should be %s", conf.Root.Level, "error")
+	}
+
+	t.Setenv("SOBA_ROOT_ADDITIVE", "maybe")
+
+	_, err = soba.Create()
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+}
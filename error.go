@@ -0,0 +1,103 @@
+package soba
+
+import (
+	"fmt"
+)
+
+const (
+	// ErrorMessageKey is the json key used for an error message.
+	ErrorMessageKey = "message"
+	// ErrorTypeKey is the json key used for an error concrete type.
+	ErrorTypeKey = "type"
+	// ErrorCausesKey is the json key used for an error causes.
+	ErrorCausesKey = "causes"
+)
+
+// maxErrorCauses defines the maximum number of causes unwrapped from an error.
+// It protects the encoder against an error that unwraps itself.
+const maxErrorCauses = 32
+
+// errorObject is an ObjectMarshaler that encodes an error with its message, its concrete type and its causes.
+// If the error implements ObjectMarshaler, it will also register its own properties in the object.
+type errorObject struct {
+	err error
+	// chained defines if this error is already part of a flattened chain of causes.
+	// In that case, only a joined error will encode its causes.
+	chained bool
+}
+
+// Encode registers error properties in the logging context.
+func (object errorObject) Encode(encoder ObjectEncoder) {
+	encoder.AddString(ErrorMessageKey, object.err.Error())
+	encoder.AddString(ErrorTypeKey, fmt.Sprintf("%T", object.err))
+
+	marshaler, ok := object.err.(ObjectMarshaler)
+	if ok {
+		marshaler.Encode(encoder)
+	}
+
+	if object.chained && !isJoinedError(object.err) {
+		return
+	}
+
+	causes := getErrorCauses(object.err)
+	if len(causes) > 0 {
+		encoder.AddArray(ErrorCausesKey, causes)
+	}
+}
+
+// errorCauses is an ArrayMarshaler that encodes a list of error.
+type errorCauses []errorObject
+
+// Encode registers every error of the list in the logging context.
+func (causes errorCauses) Encode(encoder ArrayEncoder) {
+	for i := range causes {
+		encoder.AppendObject(causes[i])
+	}
+}
+
+// getErrorCauses returns the causes of given error.
+//
+// If the error is wrapped with "Unwrap() error", the whole chain is flattened, from the outermost cause to
+// the root one. However, if the error is joined with "Unwrap() []error", every joined error is returned:
+// they will encode their own chain of causes.
+func getErrorCauses(err error) errorCauses {
+	causes := errorCauses{}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if ok {
+		for _, cause := range joined.Unwrap() {
+			if cause != nil && len(causes) < maxErrorCauses {
+				causes = append(causes, errorObject{err: cause})
+			}
+		}
+		return causes
+	}
+
+	for len(causes) < maxErrorCauses {
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+
+		err = wrapper.Unwrap()
+		if err == nil {
+			break
+		}
+
+		causes = append(causes, errorObject{err: err, chained: true})
+
+		// A joined error will encode its own list of causes.
+		if isJoinedError(err) {
+			break
+		}
+	}
+
+	return causes
+}
+
+// isJoinedError returns if given error is composed of multiple errors.
+func isJoinedError(err error) bool {
+	_, ok := err.(interface{ Unwrap() []error })
+	return ok
+}
@@ -2,15 +2,23 @@ package soba
 
 import (
 	"fmt"
-	"strings"
+	"math"
+	"os"
+	"runtime/debug"
 	"time"
 )
 
 // A Field is an operation that add a key-value pair to the logger's context.
 // Most fields are lazily marshaled, so it's inexpensive to add fields to disabled debug-level log statements.
+//
+// A Field is a typed union: its type defines which member holds the value, so a scalar value, such as an int or
+// a string, is stored without memory allocation.
 type Field struct {
 	name    string
-	handler func(Encoder)
+	kind    fieldType
+	integer int64
+	str     string
+	value   interface{}
 }
 
 // Name returns field key.
@@ -20,14 +28,216 @@ func (field Field) Name() string {
 
 // Write marshaled current field to given encoder so its key-value pair will be available in logger's context.
 func (field Field) Write(encoder Encoder) {
-	field.handler(encoder)
+	field.write(encoder, field.name)
 }
 
 // NewField creates a new field.
+// Please note that given handler is responsible of the key written on the encoder: it will not be normalized
+// with the key policy of the logger.
 func NewField(name string, handler func(Encoder)) Field {
 	return Field{
-		name:    strings.ToLower(name),
-		handler: handler,
+		name:  name,
+		kind:  fieldCustom,
+		value: handler,
+	}
+}
+
+// newField creates a new field whose handler receives its key, once normalized by the key policy.
+func newField(name string, handler func(encoder Encoder, key string)) Field {
+	return Field{
+		name:  name,
+		kind:  fieldHandler,
+		value: handler,
+	}
+}
+
+// isRedactable returns if the field value could be, or could contain, a Redactable value.
+func (field Field) isRedactable() bool {
+	switch field.kind {
+	case fieldHandler, fieldCustom, fieldLazy, fieldGroup, fieldObject, fieldObjects, fieldArray, fieldError, fieldErrors,
+		fieldStringer, fieldStringers:
+		return true
+	default:
+		return false
+	}
+}
+
+// fieldType defines which member of a Field holds its value, and how it's written on an encoder.
+type fieldType uint8
+
+const (
+	// fieldSkip is a no-op field: it's also the type of a zero Field.
+	fieldSkip = fieldType(iota)
+	fieldNull
+	fieldNamespace
+	fieldGroup
+	fieldHandler
+	fieldCustom
+	fieldLazy
+	fieldObject
+	fieldObjects
+	fieldArray
+	fieldError
+	fieldErrors
+	fieldInt
+	fieldInt8
+	fieldInt16
+	fieldInt32
+	fieldInt64
+	fieldUint
+	fieldUint8
+	fieldUint16
+	fieldUint32
+	fieldUint64
+	fieldFloat32
+	fieldFloat64
+	fieldString
+	fieldStringer
+	fieldTime
+	fieldTimeFull
+	fieldDuration
+	fieldBool
+	fieldBinary
+	fieldInts
+	fieldInt8s
+	fieldInt16s
+	fieldInt32s
+	fieldInt64s
+	fieldUints
+	fieldUint8s
+	fieldUint16s
+	fieldUint32s
+	fieldUint64s
+	fieldFloat32s
+	fieldFloat64s
+	fieldStrings
+	fieldStringers
+	fieldTimes
+	fieldDurations
+	fieldBools
+)
+
+var (
+	// minTime is the minimum time that can be stored as nanoseconds in a Field.
+	minTime = time.Unix(0, math.MinInt64)
+	// maxTime is the maximum time that can be stored as nanoseconds in a Field.
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// write marshals current field to given encoder using given key.
+// nolint: gocyclo
+func (field Field) write(encoder Encoder, key string) {
+	switch field.kind {
+	case fieldSkip, fieldNamespace:
+	case fieldNull:
+		encoder.AddNull(key)
+	case fieldGroup:
+		group := field.value.([]Field)
+		set := newFieldSet(len(group), keyFormatter{})
+		set.addAll(group)
+		encoder.AddObject(key, set)
+	case fieldHandler:
+		field.value.(func(encoder Encoder, key string))(encoder, key)
+	case fieldCustom:
+		field.value.(func(Encoder))(encoder)
+	case fieldLazy:
+		field.value.(func() Field)().write(encoder, key)
+	case fieldObject:
+		encoder.AddObject(key, field.value.(ObjectMarshaler))
+	case fieldObjects:
+		encoder.AddObjects(key, field.value.([]ObjectMarshaler))
+	case fieldArray:
+		encoder.AddArray(key, field.value.(ArrayMarshaler))
+	case fieldError:
+		encoder.AddObject(key, errorObject{err: field.value.(error)})
+	case fieldErrors:
+		errors := field.value.([]error)
+		list := make([]string, 0, len(errors))
+		for i := range errors {
+			list = append(list, errors[i].Error())
+		}
+		encoder.AddStrings(key, list)
+	case fieldInt:
+		encoder.AddInt(key, int(field.integer))
+	case fieldInt8:
+		encoder.AddInt8(key, int8(field.integer))
+	case fieldInt16:
+		encoder.AddInt16(key, int16(field.integer))
+	case fieldInt32:
+		encoder.AddInt32(key, int32(field.integer))
+	case fieldInt64:
+		encoder.AddInt64(key, field.integer)
+	case fieldUint:
+		encoder.AddUint(key, uint(field.integer))
+	case fieldUint8:
+		encoder.AddUint8(key, uint8(field.integer))
+	case fieldUint16:
+		encoder.AddUint16(key, uint16(field.integer))
+	case fieldUint32:
+		encoder.AddUint32(key, uint32(field.integer))
+	case fieldUint64:
+		encoder.AddUint64(key, uint64(field.integer))
+	case fieldFloat32:
+		encoder.AddFloat32(key, math.Float32frombits(uint32(field.integer)))
+	case fieldFloat64:
+		encoder.AddFloat64(key, math.Float64frombits(uint64(field.integer)))
+	case fieldString:
+		encoder.AddString(key, field.str)
+	case fieldStringer:
+		encoder.AddStringer(key, field.value.(fmt.Stringer))
+	case fieldTime:
+		encoder.AddTime(key, time.Unix(0, field.integer).In(field.value.(*time.Location)))
+	case fieldTimeFull:
+		encoder.AddTime(key, field.value.(time.Time))
+	case fieldDuration:
+		encoder.AddDuration(key, time.Duration(field.integer))
+	case fieldBool:
+		encoder.AddBool(key, field.integer == 1)
+	case fieldBinary:
+		encoder.AddBinary(key, field.value.([]byte))
+	default:
+		field.writeSlice(encoder, key)
+	}
+}
+
+// writeSlice marshals current field, which contains a slice, to given encoder using given key.
+// nolint: gocyclo
+func (field Field) writeSlice(encoder Encoder, key string) {
+	switch field.kind {
+	case fieldInts:
+		encoder.AddInts(key, field.value.([]int))
+	case fieldInt8s:
+		encoder.AddInt8s(key, field.value.([]int8))
+	case fieldInt16s:
+		encoder.AddInt16s(key, field.value.([]int16))
+	case fieldInt32s:
+		encoder.AddInt32s(key, field.value.([]int32))
+	case fieldInt64s:
+		encoder.AddInt64s(key, field.value.([]int64))
+	case fieldUints:
+		encoder.AddUints(key, field.value.([]uint))
+	case fieldUint8s:
+		encoder.AddUint8s(key, field.value.([]uint8))
+	case fieldUint16s:
+		encoder.AddUint16s(key, field.value.([]uint16))
+	case fieldUint32s:
+		encoder.AddUint32s(key, field.value.([]uint32))
+	case fieldUint64s:
+		encoder.AddUint64s(key, field.value.([]uint64))
+	case fieldFloat32s:
+		encoder.AddFloat32s(key, field.value.([]float32))
+	case fieldFloat64s:
+		encoder.AddFloat64s(key, field.value.([]float64))
+	case fieldStrings:
+		encoder.AddStrings(key, field.value.([]string))
+	case fieldStringers:
+		encoder.AddStringers(key, field.value.([]fmt.Stringer))
+	case fieldTimes:
+		encoder.AddTimes(key, field.value.([]time.Time))
+	case fieldDurations:
+		encoder.AddDurations(key, field.value.([]time.Duration))
+	case fieldBools:
+		encoder.AddBools(key, field.value.([]bool))
 	}
 }
 
@@ -37,140 +247,115 @@ func NewField(name string, handler func(Encoder)) Field {
 
 // Object creates a typesafe Field with given key and ObjectMarshaler.
 func Object(key string, value ObjectMarshaler) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddObject(key, value)
-	})
+	return Field{name: key, kind: fieldObject, value: value}
 }
 
 // Int creates a typesafe Field with given key and int.
 func Int(key string, value int) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt(key, value)
-	})
+	return Field{name: key, kind: fieldInt, integer: int64(value)}
 }
 
 // Int8 creates a typesafe Field with given key and int8.
 func Int8(key string, value int8) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt8(key, value)
-	})
+	return Field{name: key, kind: fieldInt8, integer: int64(value)}
 }
 
 // Int16 creates a typesafe Field with given key and int16.
 func Int16(key string, value int16) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt16(key, value)
-	})
+	return Field{name: key, kind: fieldInt16, integer: int64(value)}
 }
 
 // Int32 creates a typesafe Field with given key and int32.
 func Int32(key string, value int32) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt32(key, value)
-	})
+	return Field{name: key, kind: fieldInt32, integer: int64(value)}
 }
 
 // Int64 creates a typesafe Field with given key and int64.
 func Int64(key string, value int64) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt64(key, value)
-	})
+	return Field{name: key, kind: fieldInt64, integer: int64(value)}
 }
 
 // Uint creates a typesafe Field with given key and uint.
 func Uint(key string, value uint) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint(key, value)
-	})
+	return Field{name: key, kind: fieldUint, integer: int64(value)}
 }
 
 // Uint8 creates a typesafe Field with given key and uint8.
 func Uint8(key string, value uint8) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint8(key, value)
-	})
+	return Field{name: key, kind: fieldUint8, integer: int64(value)}
 }
 
 // Uint16 creates a typesafe Field with given key and uint16.
 func Uint16(key string, value uint16) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint16(key, value)
-	})
+	return Field{name: key, kind: fieldUint16, integer: int64(value)}
 }
 
 // Uint32 creates a typesafe Field with given key and uint32.
 func Uint32(key string, value uint32) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint32(key, value)
-	})
+	return Field{name: key, kind: fieldUint32, integer: int64(value)}
 }
 
 // Uint64 creates a typesafe Field with given key and uint64.
 func Uint64(key string, value uint64) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint64(key, value)
-	})
+	return Field{name: key, kind: fieldUint64, integer: int64(value)}
 }
 
 // Float32 creates a typesafe Field with given key and float32.
 func Float32(key string, value float32) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddFloat32(key, value)
-	})
+	return Field{name: key, kind: fieldFloat32, integer: int64(math.Float32bits(value))}
 }
 
 // Float64 creates a typesafe Field with given key and float64.
 func Float64(key string, value float64) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddFloat64(key, value)
-	})
+	return Field{name: key, kind: fieldFloat64, integer: int64(math.Float64bits(value))}
 }
 
 // String creates a typesafe Field with given key and string.
 func String(key, value string) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddString(key, value)
-	})
+	return Field{name: key, kind: fieldString, str: value}
 }
 
 // Stringer creates a typesafe Field with given key and Stringer.
 func Stringer(key string, value fmt.Stringer) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddStringer(key, value)
-	})
+	return Field{name: key, kind: fieldStringer, value: value}
 }
 
 // Time creates a typesafe Field with given key and Time.
 func Time(key string, value time.Time) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddTime(key, value)
-	})
+	if value.Before(minTime) || value.After(maxTime) {
+		return Field{name: key, kind: fieldTimeFull, value: value}
+	}
+	return Field{name: key, kind: fieldTime, integer: value.UnixNano(), value: value.Location()}
 }
 
 // Duration creates a typesafe Field with given key and Duration.
 func Duration(key string, value time.Duration) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddDuration(key, value)
-	})
+	return Field{name: key, kind: fieldDuration, integer: int64(value)}
 }
 
 // Bool creates a typesafe Field with given key and Bool.
 func Bool(key string, value bool) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddBool(key, value)
-	})
+	if value {
+		return Field{name: key, kind: fieldBool, integer: 1}
+	}
+	return Field{name: key, kind: fieldBool}
 }
 
 // Binary creates a typesafe Field with given key and slice of byte.
 func Binary(key string, value []byte) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddBinary(key, value)
-	})
+	return Field{name: key, kind: fieldBinary, value: value}
 }
 
 // Skip is a no-op Field
 func Skip(key string) Field {
-	return NewField(key, func(encoder Encoder) {})
+	return Field{name: key, kind: fieldSkip}
+}
+
+// Lazy creates a Field with given key whose value is computed by given function only when the field is encoded.
+// The function is called at most once per entry, even if the entry is written by several appenders. A lazy field
+// added to a logger with With is computed again for every entry: it's never part of a pre-encoded context.
+func Lazy(key string, value func() Field) Field {
+	return Field{name: key, kind: fieldLazy, value: value}
 }
 
 // Error is an alias of NamedError("error", err).
@@ -179,20 +364,219 @@ func Error(err error) Field {
 }
 
 // NamedError creates a typesafe Field with given key and error.
+// The error is encoded as an object with its message, its concrete type and its chain of causes.
+// Also, if the error implements ObjectMarshaler, it can register additional properties in this object.
 func NamedError(key string, err error) Field {
 
 	if err == nil {
 		return Skip(key)
 	}
 
-	return String(key, err.Error())
+	return Field{name: key, kind: fieldError, value: err}
 }
 
 // Null creates a typesafe Field with given key as null value.
 func Null(key string) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddNull(key)
-	})
+	return Field{name: key, kind: fieldNull}
+}
+
+// ----------------------------------------------------------------------------
+// Namespace
+// ----------------------------------------------------------------------------
+
+// Namespace creates a Field that nests every following fields in an object identified by given key.
+// For example, a logger created with logger.With(soba.Namespace("db")) will write its fields in the "db" object.
+func Namespace(key string) Field {
+	return Field{name: key, kind: fieldNamespace}
+}
+
+// Group creates a Field with given key that nests given fields in an object.
+// In case of fields with duplicate name, the last one will be kept.
+func Group(key string, fields ...Field) Field {
+	return Field{name: key, kind: fieldGroup, value: fields}
+}
+
+// fieldSet is an ordered list of fields where a field overwrites a previous one with the same name.
+// Every field key is normalized using the key formatter of the set.
+type fieldSet struct {
+	list    []Field
+	indexes map[string]int
+	keys    keyFormatter
+}
+
+// newFieldSet creates a new fieldSet with given capacity and key formatter.
+func newFieldSet(capacity int, keys keyFormatter) *fieldSet {
+	return &fieldSet{
+		list:    make([]Field, 0, capacity),
+		indexes: make(map[string]int, capacity),
+		keys:    keys,
+	}
+}
+
+// add appends given field to the set, or replaces the previous field with the same name.
+// If the field is a namespace, it returns a new nested set which should receive the following fields.
+func (set *fieldSet) add(field Field) *fieldSet {
+	if field.kind == fieldNamespace {
+		child := newFieldSet(0, set.keys.nested())
+		set.put(newObjectField(field.name, child))
+		return child
+	}
+
+	if field.kind == fieldGroup {
+		group := field.value.([]Field)
+		child := newFieldSet(len(group), set.keys.nested())
+		child.addAll(group)
+		set.put(newObjectField(field.name, child))
+		return set
+	}
+
+	set.put(field)
+	return set
+}
+
+// addAll appends given fields to the set.
+func (set *fieldSet) addAll(fields []Field) {
+	cursor := set
+	for i := range fields {
+		cursor = cursor.add(fields[i])
+	}
+}
+
+// put appends given field to the set, or replaces the previous field with the same name.
+func (set *fieldSet) put(field Field) {
+	field.name = set.keys.format(field.name)
+
+	i, ok := set.indexes[field.name]
+	if !ok {
+		set.indexes[field.name] = len(set.list)
+		set.list = append(set.list, field)
+	} else {
+		set.list[i] = field
+	}
+}
+
+// resolve computes the lazy fields of the set and its nested sets, so every encoder writes the same value.
+func (set *fieldSet) resolve() {
+	for i := range set.list {
+		switch set.list[i].kind {
+		case fieldLazy:
+			field := set.list[i].value.(func() Field)()
+			field.name = set.list[i].name
+			set.list[i] = field
+		case fieldObject:
+			child, ok := set.list[i].value.(*fieldSet)
+			if ok {
+				child.resolve()
+			}
+		}
+	}
+}
+
+// isLazy returns if the set, or one of its nested sets, contains a lazy field.
+func (set *fieldSet) isLazy() bool {
+	for i := range set.list {
+		switch set.list[i].kind {
+		case fieldLazy:
+			return true
+		case fieldObject:
+			child, ok := set.list[i].value.(*fieldSet)
+			if ok && child.isLazy() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reset removes every field of the set.
+func (set *fieldSet) reset() {
+	set.list = set.list[:0]
+	for name := range set.indexes {
+		delete(set.indexes, name)
+	}
+}
+
+// Encode writes the fields of the set in the logging context.
+func (set *fieldSet) Encode(encoder ObjectEncoder) {
+	// Fields are written using an Encoder, which is implemented by every encoder of soba.
+	parent, ok := encoder.(Encoder)
+	if !ok {
+		return
+	}
+	for i := range set.list {
+		set.list[i].Write(parent)
+	}
+}
+
+// newObjectField creates a field with given key and nested set of fields.
+func newObjectField(key string, set *fieldSet) Field {
+	return Field{name: key, kind: fieldObject, value: set}
+}
+
+// ----------------------------------------------------------------------------
+// Builtin
+// ----------------------------------------------------------------------------
+
+const (
+	// HostnameBuiltinField defines the built-in field with the host name.
+	HostnameBuiltinField = "hostname"
+	// PidBuiltinField defines the built-in field with the process id.
+	PidBuiltinField = "pid"
+	// VersionBuiltinField defines the built-in field with the build version of the main module.
+	VersionBuiltinField = "version"
+)
+
+// BuiltinField creates a Field with the value of given built-in field, such as "hostname", "pid" or "version".
+// The field key is the built-in field name.
+func BuiltinField(name string) (Field, bool) {
+	switch name {
+	case HostnameBuiltinField:
+		hostname, err := os.Hostname()
+		if err != nil {
+			return Null(name), true
+		}
+		return String(name, hostname), true
+
+	case PidBuiltinField:
+		return Int(name, os.Getpid()), true
+
+	case VersionBuiltinField:
+		return String(name, getBuildVersion()), true
+
+	default:
+		return Skip(name), false
+	}
+}
+
+// IsBuiltinFieldNameValid verify that a built-in field name is allowed.
+// Unlike BuiltinField, it doesn't resolve the field value.
+func IsBuiltinFieldNameValid(name string) bool {
+	switch name {
+	case HostnameBuiltinField, PidBuiltinField, VersionBuiltinField:
+		return true
+	default:
+		return false
+	}
+}
+
+// getBuildVersion returns the build version of the main module, or its vcs revision if it's a development build.
+func getBuildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+
+	return "(devel)"
 }
 
 // ----------------------------------------------------------------------------
@@ -201,144 +585,100 @@ func Null(key string) Field {
 
 // Objects creates a typesafe Field with given key and collection of ObjectMarshaler.
 func Objects(key string, values []ObjectMarshaler) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddObjects(key, values)
-	})
+	return Field{name: key, kind: fieldObjects, value: values}
 }
 
 // Array creates a typesafe Field with given key and ArrayMarshaler.
 func Array(key string, value ArrayMarshaler) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddArray(key, value)
-	})
+	return Field{name: key, kind: fieldArray, value: value}
 }
 
 // Ints creates a typesafe Field with given key and slice of int.
 func Ints(key string, values []int) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInts(key, values)
-	})
+	return Field{name: key, kind: fieldInts, value: values}
 }
 
 // Int8s creates a typesafe Field with given key and slice of int8.
 func Int8s(key string, values []int8) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt8s(key, values)
-	})
+	return Field{name: key, kind: fieldInt8s, value: values}
 }
 
 // Int16s creates a typesafe Field with given key and slice of int16.
 func Int16s(key string, values []int16) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt16s(key, values)
-	})
+	return Field{name: key, kind: fieldInt16s, value: values}
 }
 
 // Int32s creates a typesafe Field with given key and slice of int32.
 func Int32s(key string, values []int32) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt32s(key, values)
-	})
+	return Field{name: key, kind: fieldInt32s, value: values}
 }
 
 // Int64s creates a typesafe Field with given key and slice of int64.
 func Int64s(key string, values []int64) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddInt64s(key, values)
-	})
+	return Field{name: key, kind: fieldInt64s, value: values}
 }
 
 // Uints creates a typesafe Field with given key and slice of uint.
 func Uints(key string, values []uint) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUints(key, values)
-	})
+	return Field{name: key, kind: fieldUints, value: values}
 }
 
 // Uint8s creates a typesafe Field with given key and slice of uint8.
 func Uint8s(key string, values []uint8) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint8s(key, values)
-	})
+	return Field{name: key, kind: fieldUint8s, value: values}
 }
 
 // Uint16s creates a typesafe Field with given key and slice of uint16.
 func Uint16s(key string, values []uint16) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint16s(key, values)
-	})
+	return Field{name: key, kind: fieldUint16s, value: values}
 }
 
 // Uint32s creates a typesafe Field with given key and slice of uint32.
 func Uint32s(key string, values []uint32) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint32s(key, values)
-	})
+	return Field{name: key, kind: fieldUint32s, value: values}
 }
 
 // Uint64s creates a typesafe Field with given key and slice of uint64.
 func Uint64s(key string, values []uint64) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddUint64s(key, values)
-	})
+	return Field{name: key, kind: fieldUint64s, value: values}
 }
 
 // Float32s creates a typesafe Field with given key and slice of float32.
 func Float32s(key string, values []float32) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddFloat32s(key, values)
-	})
+	return Field{name: key, kind: fieldFloat32s, value: values}
 }
 
 // Float64s creates a typesafe Field with given key and slice of float64.
 func Float64s(key string, values []float64) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddFloat64s(key, values)
-	})
+	return Field{name: key, kind: fieldFloat64s, value: values}
 }
 
 // Strings creates a typesafe Field with given key and slice of string.
 func Strings(key string, values []string) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddStrings(key, values)
-	})
+	return Field{name: key, kind: fieldStrings, value: values}
 }
 
 // Stringers creates a typesafe Field with given key and slice of Stringer.
 func Stringers(key string, values []fmt.Stringer) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddStringers(key, values)
-	})
+	return Field{name: key, kind: fieldStringers, value: values}
 }
 
 // Times creates a typesafe Field with given key and slice of Time.
 func Times(key string, values []time.Time) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddTimes(key, values)
-	})
+	return Field{name: key, kind: fieldTimes, value: values}
 }
 
 // Durations creates a typesafe Field with given key and slice of Duration.
 func Durations(key string, values []time.Duration) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddDurations(key, values)
-	})
+	return Field{name: key, kind: fieldDurations, value: values}
 }
 
 // Bools creates a typesafe Field with given key and slice of boolean.
 func Bools(key string, values []bool) Field {
-	return NewField(key, func(encoder Encoder) {
-		encoder.AddBools(key, values)
-	})
+	return Field{name: key, kind: fieldBools, value: values}
 }
 
 // Errors creates a typesafe Field with given key and slice of error.
 func Errors(key string, errors []error) Field {
-	return NewField(key, func(encoder Encoder) {
-		list := []string{}
-		for i := range errors {
-			list = append(list, errors[i].Error())
-		}
-		encoder.AddStrings(key, list)
-	})
+	return Field{name: key, kind: fieldErrors, value: errors}
 }
@@ -270,6 +270,19 @@ func TestField_Time(t *testing.T) {
 	}
 }
 
+// Test field with Time that cannot be stored as nanoseconds.
+func TestField_TimeOutOfRange(t *testing.T) {
+	when := time.Date(2400, 1, 2, 3, 4, 5, 0, time.UTC)
+	field := soba.Time("key", when)
+	expected := `"key":"2400-01-02T03:04:05Z"`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
 // Test field with time.Duration.
 func TestField_Duration(t *testing.T) {
 	latency := (2 * time.Millisecond) + (523 * time.Microsecond)
@@ -311,7 +324,7 @@ func TestField_Binary(t *testing.T) {
 func TestField_Error(t *testing.T) {
 	err := fmt.Errorf("foobar")
 	field := soba.Error(err)
-	expected := `"error":"foobar"`
+	expected := `"error":{"message":"foobar","type":"*errors.errorString"}`
 
 	value := DebugField(field)
 
@@ -324,7 +337,124 @@ func TestField_Error(t *testing.T) {
 func TestField_NamedError(t *testing.T) {
 	err := fmt.Errorf("foobar")
 	field := soba.NamedError("validation", err)
-	expected := `"validation":"foobar"`
+	expected := `"validation":{"message":"foobar","type":"*errors.errorString"}`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// TestError is an error that implements ObjectMarshaler for test.
+type TestError struct {
+	Code int
+}
+
+func (e TestError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.Code)
+}
+
+func (e TestError) Encode(encoder soba.ObjectEncoder) {
+	encoder.AddInt("code", e.Code)
+}
+
+// Test field with a chain of wrapped errors.
+func TestField_WrappedError(t *testing.T) {
+	root := errors.New("connection refused")
+	err := fmt.Errorf("cannot create user: %w", fmt.Errorf("cannot execute query: %w", root))
+	field := soba.Error(err)
+	expected := fmt.Sprint(
+		`"error":{"message":"cannot create user: cannot execute query: connection refused",`,
+		`"type":"*fmt.wrapError","causes":[`,
+		`{"message":"cannot execute query: connection refused","type":"*fmt.wrapError"},`,
+		`{"message":"connection refused","type":"*errors.errorString"}]}`,
+	)
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with joined errors.
+func TestField_JoinedError(t *testing.T) {
+	err := fmt.Errorf("cannot flush: %w", errors.Join(
+		errors.New("disk full"),
+		fmt.Errorf("cannot notify: %w", TestError{Code: 503}),
+	))
+	field := soba.Error(err)
+	expected := fmt.Sprint(
+		`"error":{"message":"cannot flush: disk full\ncannot notify: unexpected status code: 503",`,
+		`"type":"*fmt.wrapError","causes":[`,
+		`{"message":"disk full\ncannot notify: unexpected status code: 503","type":"*errors.joinError","causes":[`,
+		`{"message":"disk full","type":"*errors.errorString"},`,
+		`{"message":"cannot notify: unexpected status code: 503","type":"*fmt.wrapError","causes":[`,
+		`{"message":"unexpected status code: 503","type":"soba_test.TestError","code":503}]}]}]}`,
+	)
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with error that implements ObjectMarshaler.
+func TestField_MarshalerError(t *testing.T) {
+	field := soba.NamedError("upstream", TestError{Code: 404})
+	expected := `"upstream":{"message":"unexpected status code: 404","type":"soba_test.TestError","code":404}`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with a group of fields.
+func TestField_Group(t *testing.T) {
+	field := soba.Group("http",
+		soba.String("method", "GET"),
+		soba.Int("status", 500),
+		soba.Group("client", soba.String("ip", "10.0.7.23")),
+		soba.Int("status", 200),
+	)
+	expected := `"http":{"method":"GET","status":200,"client":{"ip":"10.0.7.23"}}`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test field with a namespace in a group of fields.
+func TestField_GroupNamespace(t *testing.T) {
+	field := soba.Group("http",
+		soba.String("method", "GET"),
+		soba.Namespace("response"),
+		soba.Int("status", 200),
+		soba.String("method", "POST"),
+	)
+	expected := `"http":{"method":"GET","response":{"status":200,"method":"POST"}}`
+
+	value := DebugField(field)
+
+	if expected != value {
+		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
+	}
+}
+
+// Test namespace field written without an entry.
+func TestField_Namespace(t *testing.T) {
+	field := soba.Namespace("db")
+	expected := ``
+
+	if field.Name() != "db" {
+		t.Fatalf("Unexpected field name: '%s' should be '%s'", field.Name(), "db")
+	}
 
 	value := DebugField(field)
 
@@ -673,3 +803,30 @@ func TestField_Errors(t *testing.T) {
 		t.Fatalf("Unexpected value: '%s' should be '%s'", value, expected)
 	}
 }
+
+// Test that a built-in field name is valid if, and only if, a built-in field is created with it.
+func TestField_IsBuiltinFieldNameValid(t *testing.T) {
+	scenarios := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "hostname", valid: true},
+		{name: "pid", valid: true},
+		{name: "version", valid: true},
+		{name: "Hostname", valid: false},
+		{name: "uptime", valid: false},
+		{name: "", valid: false},
+	}
+
+	for i, scenario := range scenarios {
+		message := fmt.Sprintf("scenario #%d", (i + 1))
+		result := soba.IsBuiltinFieldNameValid(scenario.name)
+		if result != scenario.valid {
+			t.Fatalf("Unexpected result for %s: %v should be %v", message, result, scenario.valid)
+		}
+		_, ok := soba.BuiltinField(scenario.name)
+		if ok != scenario.valid {
+			t.Fatalf("Unexpected built-in field for %s: %v should be %v", message, ok, scenario.valid)
+		}
+	}
+}
@@ -1,12 +1,21 @@
 module github.com/novln/soba
 
+go 1.21
+
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/Pallinder/go-randomdata v1.2.0
 	github.com/jawher/mow.cli v1.2.0
-	github.com/mattn/go-colorable v0.1.2 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/valyala/fastjson v1.6.3
 	github.com/zchee/color v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	golang.org/x/sys v0.0.0-20190222072716-a9d3bda3a223 // indirect
 	golang.org/x/text v0.3.2 // indirect
-	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
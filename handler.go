@@ -1,10 +1,13 @@
 package soba
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 )
@@ -14,8 +17,18 @@ import (
 type Handler interface {
 	// New creates a new Logger using given name.
 	New(name string) Logger
+	// Root returns the root logger, which is the ancestor of every other logger.
+	Root() Logger
+	// Sync flushes the buffered log entries of the handler appenders.
+	Sync() error
+	// Shutdown drains and closes the handler appenders, unless given context is done before.
+	// Afterward, its loggers discard every log entry.
+	Shutdown(ctx context.Context) error
 	// Close recycles the handler appenders.
 	Close() error
+	// Loggers returns how every logger of the handler is resolved: its effective level, its appenders and where
+	// they are inherited from.
+	Loggers() []LoggerInfo
 }
 
 // Create provides an alternative way to obtain loggers if the context based approach doesn't
@@ -26,6 +39,8 @@ type Handler interface {
 //  - Then, it will lookup from current directory if a configuration file exists.
 //  - Finally, it will create a new instance with default configurations.
 //
+// In every case, the configuration is overridden by environment variables, as described by EnvConfigPrefix.
+//
 // For specific configurations, please uses either CreateWithConfig or CreateWithFile.
 func Create() (Handler, error) {
 	path := os.Getenv(EnvConfigPath)
@@ -37,18 +52,26 @@ func Create() (Handler, error) {
 		return CreateWithFile(DefaultConfigPath)
 	}
 
-	return CreateWithConfig(NewDefaultConfig())
+	conf, err := newEnvDefaultConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateWithConfig(conf)
 }
 
 // CreateWithFile provides an alternative way to obtain loggers if the context based approach doesn't
 // fit your requirements. It will creates a new handler using given file path.
+// The warnings of its configuration are written on stderr.
 func CreateWithFile(path string) (Handler, error) {
 	conf, err := ParseConfig(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return create(conf)
+	onConfigWarnings(conf)
+
+	return create(conf, defaultRegistry)
 }
 
 // CreateWithConfig provides an alternative way to obtain loggers if the context based approach doesn't
@@ -59,7 +82,23 @@ func CreateWithConfig(conf *Config) (Handler, error) {
 		return nil, errors.Wrap(err, "configuration is invalid")
 	}
 
-	return create(conf)
+	return create(conf, defaultRegistry)
+}
+
+// CreateWithRegistry creates a new handler using given configuration, whose appenders are created with the
+// appender types of given registry, rather than the ones registered with RegisterAppenderType.
+// It allows to scope custom appender types to a handler, for example in tests.
+func CreateWithRegistry(conf *Config, registry *Registry) (Handler, error) {
+	if registry == nil {
+		return nil, errors.New("registry is required")
+	}
+
+	err := validateConfig(conf, registry)
+	if err != nil {
+		return nil, errors.Wrap(err, "configuration is invalid")
+	}
+
+	return create(conf, registry)
 }
 
 // A handler contains every required components to provides loggers.
@@ -67,18 +106,30 @@ type handler struct {
 	conf      Config
 	appenders map[string]Appender
 	loggers   sync.Map
+	// infos describes how the root logger and the configured loggers are resolved.
+	infos    map[string]LoggerInfo
+	redactor *Redactor
+	// closed is set once the handler is shut down, so its loggers discard every log entry.
+	closed *uint32
 }
 
-// create a handler using given configuration.
-func create(conf *Config) (*handler, error) {
+// create a handler using given configuration, and the appender types of given registry.
+func create(conf *Config, registry *Registry) (*handler, error) {
 
 	handler := &handler{
 		conf:      *conf,
 		appenders: map[string]Appender{},
 		loggers:   sync.Map{},
+		infos:     map[string]LoggerInfo{},
+		closed:    new(uint32),
+	}
+
+	err := createRedactor(conf, handler)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create soba handler")
 	}
 
-	err := createAppenders(conf, handler)
+	err = createAppenders(conf, registry, handler)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create soba handler")
 	}
@@ -96,34 +147,34 @@ func create(conf *Config) (*handler, error) {
 	return handler, nil
 }
 
-func closePreviousAppender(name string, handler *handler) {
-	// In case there is a duplication in appenders name, we close the previous one.
-	appender, ok := handler.appenders[name]
-	if ok && appender != nil {
-		// Silent the error.
-		_ = appender.Close()
+func createRedactor(conf *Config, handler *handler) error {
+
+	redactor, err := NewRedactor(conf.Redact)
+	if err != nil {
+		return err
+	}
+
+	if !redactor.IsEmpty() {
+		handler.redactor = redactor
 	}
+
+	return nil
 }
 
-func createAppenders(conf *Config, handler *handler) error {
+func createAppenders(conf *Config, registry *Registry, handler *handler) error {
 
 	for name := range conf.Appenders {
-		closePreviousAppender(name, handler)
-		appender, err := NewAppender(name, conf.Appenders[name])
+		appender, err := registry.NewAppender(name, conf.Appenders[name])
 		if err != nil {
+			// Release the appenders already created.
+			for _, other := range handler.appenders {
+				_ = other.Close()
+			}
 			return err
 		}
 		handler.appenders[name] = appender
 	}
 
-	plMutex.Lock()
-	defer plMutex.Unlock()
-
-	for name, appender := range plAppenders {
-		closePreviousAppender(name, handler)
-		handler.appenders[name] = appender
-	}
-
 	return nil
 }
 
@@ -139,11 +190,73 @@ func createRootLogger(conf *Config, handler *handler) error {
 		return err
 	}
 
-	handler.loggers.Store("", NewLogger("root", level, appenders))
+	logger := NewLogger("root", level, appenders)
+	logger.closed = handler.closed
+	logger.keys = getKeyFormatter(conf)
+	logger.redactor = handler.redactor
+	logger = logger.With(getFieldsForLogger(conf, "")...)
+
+	handler.loggers.Store("", logger)
+	handler.infos[""] = getLoggerInfo(conf, "", level)
 
 	return nil
 }
 
+func getKeyFormatter(conf *Config) keyFormatter {
+	// Key policy is verified by validateKeysConfig function.
+	policy, _ := ParseKeyPolicy(conf.Keys.Policy)
+
+	return keyFormatter{
+		policy:  policy,
+		protect: conf.Keys.Protect,
+	}
+}
+
+// getFieldsForLogger returns the static fields of the logger identified by given name (an empty name for the
+// root logger). They are composed of the built-in fields, the global fields, and the fields of the root logger
+// and every configured ancestor: a descendant overwrites a field with the same key.
+func getFieldsForLogger(conf *Config, name string) []Field {
+
+	set := newFieldSet(0, keyFormatter{})
+
+	for _, builtin := range conf.Builtins {
+		field, ok := BuiltinField(builtin)
+		if ok {
+			set.put(field)
+		}
+	}
+
+	addFieldsFromConfig(set, conf.Fields)
+	addFieldsFromConfig(set, conf.Root.Fields)
+
+	if name != "" {
+		hierarchy := strings.Split(name, ".")
+		for i := 1; i <= len(hierarchy); i++ {
+			current := strings.Join(hierarchy[0:i], ".")
+			parent, ok := conf.Loggers[current]
+			if ok {
+				addFieldsFromConfig(set, parent.Fields)
+			}
+		}
+	}
+
+	return set.list
+}
+
+func addFieldsFromConfig(set *fieldSet, fields map[string]interface{}) {
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		set.put(Any(key, fields[key]))
+	}
+}
+
 func getLoggerLevel(conf ConfigLogger, name string) (Level, error) {
 
 	level, ok := ParseLevel(conf.Level)
@@ -154,22 +267,15 @@ func getLoggerLevel(conf ConfigLogger, name string) (Level, error) {
 	return level, nil
 }
 
-func getParentAppendersForLogger(conf *Config, handler *handler, hierarchy []string, result map[string]Appender) error {
-
-	length := len(hierarchy)
-	for i := 1; i < length; i++ {
-
-		cursor := length - i
-		list := hierarchy[0:cursor]
-		current := strings.Join(list, ".")
+// getLevelForChildLogger returns the level of the logger identified by given name, which could be inherited.
+func getLevelForChildLogger(conf *Config, name string) (Level, error) {
 
-		parent, ok := conf.Loggers[current]
-		if ok {
-			return getLocalAppendersForLogger(parent, handler, result)
-		}
+	origin := getLevelOrigin(conf, name)
+	if origin == "" {
+		return getLoggerLevel(conf.Root, "root")
 	}
 
-	return getLocalAppendersForLogger(conf.Root, handler, result)
+	return getLoggerLevel(conf.Loggers[origin], origin)
 }
 
 func getLocalAppendersForLogger(conf ConfigLogger, handler *handler, result map[string]Appender) error {
@@ -194,40 +300,107 @@ func getAppendersForRootLogger(conf *Config, handler *handler) ([]Appender, erro
 		return nil, err
 	}
 
-	list := []Appender{}
-	for _, appender := range appenders {
-		list = append(list, appender)
-	}
-
-	return list, nil
+	return getAppendersList(appenders), nil
 }
 
 func getAppendersForChildLogger(conf *Config, handler *handler, name string) ([]Appender, error) {
 
 	appenders := map[string]Appender{}
 
-	err := getLocalAppendersForLogger(conf.Loggers[name], handler, appenders)
-	if err != nil {
-		return nil, err
-	}
-
-	if isChildLoggerAdditive(conf, name, appenders) {
-		hierarchy := strings.Split(name, ".")
-		err = getParentAppendersForLogger(conf, handler, hierarchy, appenders)
+	for _, origin := range getAppendersOrigins(conf, name) {
+		err := getLocalAppendersForLogger(getLoggerConfig(conf, origin), handler, appenders)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	list := []Appender{}
-	for _, appender := range appenders {
-		list = append(list, appender)
+	return getAppendersList(appenders), nil
+}
+
+// getAppendersList returns given appenders, sorted by name.
+func getAppendersList(appenders map[string]Appender) []Appender {
+
+	names := make([]string, 0, len(appenders))
+	for name := range appenders {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	list := make([]Appender, 0, len(names))
+	for _, name := range names {
+		list = append(list, appenders[name])
+	}
+
+	return list
+}
+
+// getAppendersOrigins returns the names of the loggers whose appenders are associated with the logger identified
+// by given name: the logger itself, and then its configured ancestors while they are additive, up to the root
+// logger, which is identified by an empty name.
+func getAppendersOrigins(conf *Config, name string) []string {
+
+	origins := []string{name}
+	if !isChildLoggerAdditive(conf, name) {
+		return origins
+	}
+
+	for _, ancestor := range getLoggerAncestors(conf, name) {
+		origins = append(origins, ancestor)
+		if ancestor == "" || !isChildLoggerAdditive(conf, ancestor) {
+			break
+		}
+	}
+
+	return origins
+}
+
+// getLevelOrigin returns the name of the logger that defines the level of the logger identified by given name:
+// either the logger itself, or its nearest configured ancestor that defines one, or the root logger, which is
+// identified by an empty name.
+func getLevelOrigin(conf *Config, name string) string {
+
+	if name == "" || conf.Loggers[name].Level != "" {
+		return name
 	}
 
-	return list, nil
+	for _, ancestor := range getLoggerAncestors(conf, name) {
+		if getLoggerConfig(conf, ancestor).Level != "" {
+			return ancestor
+		}
+	}
+
+	return ""
+}
+
+// getLoggerAncestors returns the names of the configured ancestors of the logger identified by given name, from the
+// nearest to the farthest. The list always ends with the root logger, which is identified by an empty name.
+func getLoggerAncestors(conf *Config, name string) []string {
+
+	ancestors := []string{}
+
+	hierarchy := strings.Split(name, ".")
+	for cursor := len(hierarchy) - 1; cursor > 0; cursor-- {
+		current := strings.Join(hierarchy[0:cursor], ".")
+		_, ok := conf.Loggers[current]
+		if ok {
+			ancestors = append(ancestors, current)
+		}
+	}
+
+	return append(ancestors, "")
+}
+
+// getLoggerConfig returns the configuration of the logger identified by given name, or the root logger if the
+// name is empty.
+func getLoggerConfig(conf *Config, name string) ConfigLogger {
+	if name == "" {
+		return conf.Root
+	}
+	return conf.Loggers[name]
 }
 
-func isChildLoggerAdditive(conf *Config, name string, appenders map[string]Appender) bool {
+func isChildLoggerAdditive(conf *Config, name string) bool {
 	// If logger is defined as additive, then it is.
 	if conf.Loggers[name].Additive {
 		return true
@@ -240,7 +413,7 @@ func isChildLoggerAdditive(conf *Config, name string, appenders map[string]Appen
 	}
 
 	// If there is no appender defined and the logger is not disabled, use the parent appenders as default.
-	if len(appenders) == 0 {
+	if len(conf.Loggers[name].Appenders) == 0 {
 		return true
 	}
 
@@ -251,7 +424,7 @@ func createChildLoggers(conf *Config, handler *handler) error {
 
 	for name := range conf.Loggers {
 
-		level, err := getLoggerLevel(conf.Loggers[name], name)
+		level, err := getLevelForChildLogger(conf, name)
 		if err != nil {
 			return err
 		}
@@ -261,7 +434,14 @@ func createChildLoggers(conf *Config, handler *handler) error {
 			return err
 		}
 
-		handler.loggers.Store(name, NewLogger(name, level, appenders))
+		logger := NewLogger(name, level, appenders)
+		logger.closed = handler.closed
+		logger.keys = getKeyFormatter(conf)
+		logger.redactor = handler.redactor
+		logger = logger.With(getFieldsForLogger(conf, name)...)
+
+		handler.loggers.Store(name, logger)
+		handler.infos[name] = getLoggerInfo(conf, name, level)
 
 	}
 
@@ -306,15 +486,160 @@ func (handler *handler) New(name string) Logger {
 	return val.(Logger)
 }
 
-// Close recycles the handler appenders.
-// If case of one or multiple errors, we return the first one.
-func (handler *handler) Close() error {
-	var err error
+// Root returns the root logger, which is the ancestor of every other logger.
+func (handler *handler) Root() Logger {
+	val, ok := handler.loggers.Load("")
+	if !ok {
+		panic("soba: root logger must be defined")
+	}
+
+	return val.(Logger)
+}
+
+// Sync flushes the buffered log entries of the handler appenders, if they implement Syncer.
+// In case of one or multiple errors, we return a MultiError with an AppenderError for every failing appender.
+func (handler *handler) Sync() error {
+	list := MultiError{}
 	for name, appender := range handler.appenders {
-		thr := appender.Close()
-		if thr != nil && err == nil {
-			err = errors.Wrapf(thr, "cannot close appender %s", name)
+		syncer, ok := appender.(Syncer)
+		if !ok {
+			continue
 		}
+		err := syncer.Sync()
+		if err != nil {
+			list = append(list, &AppenderError{Name: name, Err: err})
+		}
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].(*AppenderError).Name < list[j].(*AppenderError).Name
+	})
+
+	return list
+}
+
+// Shutdown drains and closes the handler appenders in parallel, unless given context is done before.
+// Afterward, its loggers discard every log entry.
+// In case of one or multiple errors, including the appenders that were not closed before the context is done,
+// we return a MultiError with an AppenderError for every failing appender.
+func (handler *handler) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapUint32(handler.closed, 0, 1) {
+		return nil
 	}
-	return err
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	results := make(chan result, len(handler.appenders))
+	pending := make(map[string]struct{}, len(handler.appenders))
+
+	for name, appender := range handler.appenders {
+		pending[name] = struct{}{}
+		go func(name string, appender Appender) {
+			results <- result{
+				name: name,
+				err:  shutdownAppender(ctx, appender),
+			}
+		}(name, appender)
+	}
+
+	list := MultiError{}
+
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			delete(pending, result.name)
+			if result.err != nil {
+				list = append(list, &AppenderError{Name: result.name, Err: result.err})
+			}
+
+		case <-ctx.Done():
+			for name := range pending {
+				list = append(list, &AppenderError{Name: name, Err: ctx.Err()})
+			}
+			pending = nil
+		}
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].(*AppenderError).Name < list[j].(*AppenderError).Name
+	})
+
+	return list
+}
+
+// shutdownAppender drains and closes given appender: a Shutdowner is shut down with given context, whereas a
+// Syncer is synced before being closed.
+func shutdownAppender(ctx context.Context, appender Appender) error {
+	shutdowner, ok := appender.(Shutdowner)
+	if ok {
+		return shutdowner.Shutdown(ctx)
+	}
+
+	syncer, ok := appender.(Syncer)
+	if ok {
+		err := syncer.Sync()
+		if err != nil {
+			_ = appender.Close()
+			return err
+		}
+	}
+
+	return appender.Close()
+}
+
+// Close recycles the handler appenders.
+// It's equivalent to Shutdown without deadline.
+func (handler *handler) Close() error {
+	return handler.Shutdown(context.Background())
+}
+
+// AppenderError is an error returned by an appender.
+type AppenderError struct {
+	// Name is the appender name.
+	Name string
+	// Err is the error returned by the appender.
+	Err error
+}
+
+// Error returns the error message, prefixed by the appender name.
+func (e *AppenderError) Error() string {
+	return fmt.Sprintf("appender %s: %s", e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the appender.
+func (e *AppenderError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the error returned by the appender.
+func (e *AppenderError) Cause() error {
+	return e.Err
+}
+
+// MultiError is a list of errors, such as every appender that failed to shut down.
+type MultiError []error
+
+// Error returns the error messages, separated by a semicolon.
+func (list MultiError) Error() string {
+	messages := make([]string, len(list))
+	for i := range list {
+		messages[i] = list[i].Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the list of errors.
+func (list MultiError) Unwrap() []error {
+	return list
 }
@@ -1,10 +1,15 @@
 package soba_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	random "github.com/Pallinder/go-randomdata"
 
@@ -261,12 +266,7 @@ func TestHandler_NewLogger(t *testing.T) {
 	stdoutAppender := NewTestAppender("stdout")
 	defer CloseAppender(t, stdoutAppender)
 
-	err := soba.RegisterAppenders(apiAppender, dbAppender, authAppender, stdoutAppender)
-	if err != nil {
-		t.Fatalf("Unexpected error: %+v", err)
-	}
-
-	handler, err := soba.CreateWithConfig(&soba.Config{
+	conf := &soba.Config{
 		Root: soba.ConfigLogger{
 			Level:    "info",
 			Additive: false,
@@ -325,7 +325,10 @@ func TestHandler_NewLogger(t *testing.T) {
 				Appenders: []string{},
 			},
 		},
-	})
+	}
+	registry := NewTestRegistry(t, conf, apiAppender, dbAppender, authAppender, stdoutAppender)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
 	if err != nil {
 		t.Fatalf("Unexpected error: %+v", err)
 	}
@@ -792,3 +795,622 @@ func TestHandler_NewLogger(t *testing.T) {
 		t.Fatalf("Unexpected error: %+v", err)
 	}
 }
+
+// Test appenders additivity and level inheritance across the logger hierarchy, using logback's example.
+// nolint: gocyclo
+func TestHandler_Additivity(t *testing.T) {
+	rootAppender := NewTestAppender("root-log")
+	defer CloseAppender(t, rootAppender)
+
+	appAppender := NewTestAppender("app-log")
+	defer CloseAppender(t, appAppender)
+
+	auditAppender := NewTestAppender("app-audit")
+	defer CloseAppender(t, auditAppender)
+
+	apiAppender := NewTestAppender("api-log")
+	defer CloseAppender(t, apiAppender)
+
+	securityAppender := NewTestAppender("security-log")
+	defer CloseAppender(t, securityAppender)
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level:     "info",
+			Appenders: []string{"root-log"},
+		},
+		Loggers: map[string]soba.ConfigLogger{
+			"app": {
+				Level:     "debug",
+				Additive:  true,
+				Appenders: []string{"app-log", "app-audit"},
+			},
+			"app.web": {},
+			"app.web.api": {
+				Additive:  true,
+				Appenders: []string{"api-log"},
+			},
+			"security": {
+				Level:     "warning",
+				Additive:  false,
+				Appenders: []string{"security-log"},
+			},
+			"security.access": {},
+			"audit": {
+				Level:     "none",
+				Appenders: []string{"security-log"},
+			},
+			"audit.trail": {
+				Level:     "info",
+				Additive:  true,
+				Appenders: []string{"api-log"},
+			},
+		},
+	}
+	registry := NewTestRegistry(t, conf, rootAppender, appAppender, auditAppender, apiAppender, securityAppender)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	appenders := []*TestAppender{rootAppender, appAppender, auditAppender, apiAppender, securityAppender}
+
+	scenarios := []struct {
+		logger    string
+		level     soba.Level
+		appenders []*TestAppender
+	}{
+		{
+			logger:    "misc",
+			level:     soba.InfoLevel,
+			appenders: []*TestAppender{rootAppender},
+		},
+		{
+			logger:    "app",
+			level:     soba.DebugLevel,
+			appenders: []*TestAppender{rootAppender, appAppender, auditAppender},
+		},
+		{
+			logger:    "app.web",
+			level:     soba.DebugLevel,
+			appenders: []*TestAppender{rootAppender, appAppender, auditAppender},
+		},
+		{
+			logger:    "app.web.api",
+			level:     soba.DebugLevel,
+			appenders: []*TestAppender{rootAppender, appAppender, auditAppender, apiAppender},
+		},
+		{
+			logger:    "app.web.api.users",
+			level:     soba.DebugLevel,
+			appenders: []*TestAppender{rootAppender, appAppender, auditAppender, apiAppender},
+		},
+		{
+			logger:    "security",
+			level:     soba.WarnLevel,
+			appenders: []*TestAppender{securityAppender},
+		},
+		{
+			logger:    "security.access",
+			level:     soba.WarnLevel,
+			appenders: []*TestAppender{securityAppender},
+		},
+		{
+			logger:    "audit",
+			level:     soba.NoLevel,
+			appenders: []*TestAppender{},
+		},
+		{
+			logger:    "audit.trail",
+			level:     soba.InfoLevel,
+			appenders: []*TestAppender{apiAppender, securityAppender},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		logger := handler.New(scenario.logger)
+		if logger.Level() != scenario.level {
+			t.Fatalf("Unexpected level for logger '%s': %s should be %s",
+				scenario.logger, logger.Level(), scenario.level)
+		}
+
+		logger.Warn("Event received")
+
+		expected := map[*TestAppender]bool{}
+		for _, appender := range scenario.appenders {
+			expected[appender] = true
+		}
+
+		for _, appender := range appenders {
+			size := 0
+			if expected[appender] {
+				size = 1
+			}
+			if appender.Size() != size {
+				t.Fatalf("Unexpected number of entries for appender '%s' of logger '%s': %d should be %d",
+					appender.Name(), scenario.logger, appender.Size(), size)
+			}
+			appender.Clear()
+		}
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+// Test introspection of the logger hierarchy, for both configured loggers and loggers created with New.
+func TestHandler_Loggers(t *testing.T) {
+	stdoutAppender := NewTestAppender("stdout")
+	defer CloseAppender(t, stdoutAppender)
+
+	appAppender := NewTestAppender("app-log")
+	defer CloseAppender(t, appAppender)
+
+	apiAppender := NewTestAppender("api-log")
+	defer CloseAppender(t, apiAppender)
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level:     "info",
+			Appenders: []string{"stdout"},
+		},
+		Loggers: map[string]soba.ConfigLogger{
+			"app": {
+				Level:     "debug",
+				Additive:  true,
+				Appenders: []string{"app-log"},
+			},
+			"app.web.api": {
+				Appenders: []string{"api-log", "stdout"},
+			},
+		},
+	}
+	registry := NewTestRegistry(t, conf, stdoutAppender, appAppender, apiAppender)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	handler.New("app.web")
+	handler.New("app.web.api.users")
+
+	expected := []soba.LoggerInfo{
+		{
+			Name:        "",
+			Configured:  true,
+			Level:       soba.InfoLevel,
+			LevelOrigin: "",
+			Additive:    false,
+			Appenders: []soba.LoggerAppenderInfo{
+				{Name: "stdout", Origin: ""},
+			},
+		},
+		{
+			Name:        "app",
+			Configured:  true,
+			Parent:      "",
+			Level:       soba.DebugLevel,
+			LevelOrigin: "app",
+			Additive:    true,
+			Appenders: []soba.LoggerAppenderInfo{
+				{Name: "app-log", Origin: "app"},
+				{Name: "stdout", Origin: ""},
+			},
+		},
+		{
+			Name:        "app.web",
+			Configured:  false,
+			Parent:      "app",
+			Level:       soba.DebugLevel,
+			LevelOrigin: "app",
+			Additive:    true,
+			Appenders: []soba.LoggerAppenderInfo{
+				{Name: "app-log", Origin: "app"},
+				{Name: "stdout", Origin: ""},
+			},
+		},
+		{
+			Name:        "app.web.api",
+			Configured:  true,
+			Parent:      "app",
+			Level:       soba.DebugLevel,
+			LevelOrigin: "app",
+			Additive:    false,
+			Appenders: []soba.LoggerAppenderInfo{
+				{Name: "api-log", Origin: "app.web.api"},
+				{Name: "stdout", Origin: "app.web.api"},
+			},
+		},
+		{
+			Name:        "app.web.api.users",
+			Configured:  false,
+			Parent:      "app.web.api",
+			Level:       soba.DebugLevel,
+			LevelOrigin: "app",
+			Additive:    true,
+			Appenders: []soba.LoggerAppenderInfo{
+				{Name: "api-log", Origin: "app.web.api"},
+				{Name: "stdout", Origin: "app.web.api"},
+			},
+		},
+	}
+
+	loggers := handler.Loggers()
+	if !reflect.DeepEqual(loggers, expected) {
+		t.Fatalf("Unexpected loggers: %+v should be %+v", loggers, expected)
+	}
+
+	// Mutating the result doesn't alter the handler.
+	loggers[0].Appenders[0].Name = "other"
+	if handler.Loggers()[0].Appenders[0].Name != "stdout" {
+		t.Fatal("Unexpected mutation of handler loggers")
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+// Test static fields declared in configuration.
+// nolint: gocyclo
+func TestHandler_Fields(t *testing.T) {
+	appender := NewTestAppender("fields-log")
+	defer CloseAppender(t, appender)
+
+	conf := &soba.Config{
+		Fields: map[string]interface{}{
+			"service": "api",
+			"region":  "eu-west-1",
+		},
+		Builtins: []string{
+			soba.PidBuiltinField,
+		},
+		Root: soba.ConfigLogger{
+			Level: "info",
+			Appenders: []string{
+				"fields-log",
+			},
+			Fields: map[string]interface{}{
+				"team": "core",
+			},
+		},
+		Appenders: map[string]soba.ConfigAppender{},
+		Loggers: map[string]soba.ConfigLogger{
+			"repositories": {
+				Level: "info",
+				Appenders: []string{
+					"fields-log",
+				},
+				Fields: map[string]interface{}{
+					"component": "database",
+					"region":    "us-east-1",
+				},
+			},
+		},
+	}
+	registry := NewTestRegistry(t, conf, appender)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	handler.New("repositories.users").Info("User created", soba.String("team", "auth"))
+	handler.New("services").Info("Service started")
+
+	if appender.Size() != 2 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Size(), 2)
+	}
+
+	expected1 := fmt.Sprint(
+		`{"logger":"repositories.users","level":"info","message":"User created",`,
+		`"pid":`, os.Getpid(), `,"region":"us-east-1","service":"api","team":"auth","component":"database"}`,
+		"\n",
+	)
+	expected2 := fmt.Sprint(
+		`{"logger":"services","level":"info","message":"Service started",`,
+		`"pid":`, os.Getpid(), `,"region":"eu-west-1","service":"api","team":"core"}`,
+		"\n",
+	)
+
+	if appender.Log(0) != expected1 {
+		t.Fatalf("Unexpected log message #1: '%s' should be '%s'", appender.Log(0), expected1)
+	}
+	if appender.Log(1) != expected2 {
+		t.Fatalf("Unexpected log message #2: '%s' should be '%s'", appender.Log(1), expected2)
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+// SyncAppender is an appender that counts how many times it has been synced.
+type SyncAppender struct {
+	name  string
+	syncs int
+	err   error
+}
+
+func (appender *SyncAppender) Name() string {
+	return appender.name
+}
+
+func (SyncAppender) Close() error {
+	return nil
+}
+
+func (SyncAppender) Write(entry *soba.Entry) {}
+
+func (appender *SyncAppender) Sync() error {
+	appender.syncs++
+	return appender.err
+}
+
+// Test synchronization of handler appenders.
+func TestHandler_Sync(t *testing.T) {
+	appender := &SyncAppender{name: "sync-log"}
+	defer CloseAppender(t, appender)
+	other := &SyncAppender{name: "audit-log"}
+	defer CloseAppender(t, other)
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level: "info",
+			Appenders: []string{
+				"sync-log",
+				"audit-log",
+				"file-log",
+			},
+		},
+		Appenders: map[string]soba.ConfigAppender{
+			"file-log": {
+				Type: soba.FileAppenderType,
+				Path: "testdata/logs/sync.log",
+			},
+		},
+		Loggers: map[string]soba.ConfigLogger{},
+	}
+	registry := NewTestRegistry(t, conf, appender, other)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	defer func() {
+		err = handler.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		err = os.Remove("testdata/logs/sync.log")
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}()
+
+	handler.New("foobar").Info("Checkpoint reached")
+
+	err = handler.Sync()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	if appender.syncs != 1 {
+		t.Fatalf("Unexpected number of sync: %d should be %d", appender.syncs, 1)
+	}
+
+	appender.err = errors.New("disk is full")
+	other.err = errors.New("permission denied")
+
+	err = handler.Sync()
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	list, ok := err.(soba.MultiError)
+	if !ok {
+		t.Fatalf("Unexpected error type: %T", err)
+	}
+
+	expected := []struct {
+		name string
+		err  error
+	}{
+		{"audit-log", other.err},
+		{"sync-log", appender.err},
+	}
+
+	if len(list) != len(expected) {
+		t.Fatalf("Unexpected number of errors: %d should be %d (%s)", len(list), len(expected), err)
+	}
+
+	for i := range expected {
+		thr, ok := list[i].(*soba.AppenderError)
+		if !ok {
+			t.Fatalf("Unexpected error type: %T", list[i])
+		}
+		if thr.Name != expected[i].name || !errors.Is(thr, expected[i].err) {
+			t.Fatalf("Unexpected error #%d: %s", i+1, thr)
+		}
+	}
+
+	if !strings.Contains(err.Error(), "appender sync-log: disk is full") {
+		t.Fatalf("Unexpected error message: %s", err)
+	}
+	if appender.syncs != 2 || other.syncs != 2 {
+		t.Fatalf("Unexpected number of sync: %d and %d should be %d", appender.syncs, other.syncs, 2)
+	}
+
+	appender.err = nil
+	other.err = nil
+}
+
+// ShutdownAppender is an appender that fails or blocks when it's shut down.
+type ShutdownAppender struct {
+	name  string
+	err   error
+	block chan struct{}
+}
+
+func (appender *ShutdownAppender) Name() string {
+	return appender.name
+}
+
+func (appender *ShutdownAppender) Close() error {
+	return appender.err
+}
+
+func (ShutdownAppender) Write(entry *soba.Entry) {}
+
+func (appender *ShutdownAppender) Shutdown(ctx context.Context) error {
+	if appender.block != nil {
+		<-appender.block
+	}
+	return appender.Close()
+}
+
+// Test graceful shutdown of a handler, with aggregated errors and context deadline.
+// nolint: gocyclo
+func TestHandler_Shutdown(t *testing.T) {
+	observer := NewTestAppender("shutdown-log")
+	failing1 := &ShutdownAppender{name: "shutdown-failing-1", err: errors.New("connection reset")}
+	failing2 := &ShutdownAppender{name: "shutdown-failing-2", err: errors.New("broken pipe")}
+	blocking := &ShutdownAppender{name: "shutdown-blocking", block: make(chan struct{})}
+
+	// Once released, these appenders are shut down without error.
+	release := sync.Once{}
+	defer release.Do(func() {
+		failing1.err = nil
+		failing2.err = nil
+		close(blocking.block)
+	})
+
+	create := func() soba.Handler {
+		conf := &soba.Config{
+			Root: soba.ConfigLogger{
+				Level: "info",
+				Appenders: []string{
+					"shutdown-log",
+					"file-log",
+				},
+			},
+			Appenders: map[string]soba.ConfigAppender{
+				"file-log": {
+					Type: soba.FileAppenderType,
+					Path: "testdata/logs/shutdown.log",
+				},
+			},
+			Loggers: map[string]soba.ConfigLogger{},
+		}
+		registry := NewTestRegistry(t, conf, observer, failing1, failing2, blocking)
+
+		handler, err := soba.CreateWithRegistry(conf, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		return handler
+	}
+
+	defer func() {
+		err := os.Remove("testdata/logs/shutdown.log")
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}()
+
+	{
+		handler := create()
+		logger := handler.New("foobar")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := handler.Shutdown(ctx)
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Shutdown should respect the context deadline: %s", time.Since(start))
+		}
+
+		list, ok := err.(soba.MultiError)
+		if !ok {
+			t.Fatalf("Unexpected error type: %T", err)
+		}
+
+		expected := []struct {
+			name string
+			err  error
+		}{
+			{"shutdown-blocking", context.DeadlineExceeded},
+			{"shutdown-failing-1", failing1.err},
+			{"shutdown-failing-2", failing2.err},
+		}
+
+		if len(list) != len(expected) {
+			t.Fatalf("Unexpected number of errors: %d should be %d (%s)", len(list), len(expected), err)
+		}
+
+		for i := range expected {
+			thr, ok := list[i].(*soba.AppenderError)
+			if !ok {
+				t.Fatalf("Unexpected error type: %T", list[i])
+			}
+			if thr.Name != expected[i].name || !errors.Is(thr, expected[i].err) {
+				t.Fatalf("Unexpected error #%d: %s", i+1, thr)
+			}
+		}
+
+		if !strings.Contains(err.Error(), "appender shutdown-failing-1: connection reset") {
+			t.Fatalf("Unexpected error message: %s", err)
+		}
+
+		logger.Info("Write after shutdown")
+		if observer.Size() != 0 {
+			t.Fatalf("Unexpected number of entries: %d should be %d", observer.Size(), 0)
+		}
+
+		err = handler.Shutdown(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+	{
+		release.Do(func() {
+			failing1.err = nil
+			failing2.err = nil
+			close(blocking.block)
+		})
+
+		handler := create()
+		logger := handler.New("foobar")
+
+		logger.Info("Write before shutdown")
+		if observer.Size() != 1 {
+			t.Fatalf("Unexpected number of entries: %d should be %d", observer.Size(), 1)
+		}
+
+		err := handler.Shutdown(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		logger.Info("Write after shutdown")
+		logger.With(soba.String("foo", "bar")).Sugar().Infof("Write after %s", "shutdown")
+		if observer.Size() != 1 {
+			t.Fatalf("Unexpected number of entries: %d should be %d", observer.Size(), 1)
+		}
+
+		err = handler.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package soba
+
+import (
+	"sort"
+	"strings"
+)
+
+// A LoggerInfo describes how a logger of a handler is resolved from its configuration, in order to know where its
+// log entries are written.
+type LoggerInfo struct {
+	// Name defines the logger name, or an empty name for the root logger.
+	Name string
+	// Configured defines if the logger is declared by the configuration. Otherwise, it has been created by New, and
+	// it's a copy of its nearest configured ancestor.
+	Configured bool
+	// Parent defines the name of the nearest configured ancestor of this logger, or an empty name for the root
+	// logger. It's not defined for the root logger itself.
+	Parent string
+	// Level defines the effective level of the logger.
+	Level Level
+	// LevelOrigin defines the name of the logger that declares this level: either the logger itself, or the
+	// ancestor it's inherited from. An empty name defines the root logger.
+	LevelOrigin string
+	// Additive defines if the logger receives the appenders of its ancestors.
+	Additive bool
+	// Appenders is the list of appenders associated with this logger, sorted by name.
+	Appenders []LoggerAppenderInfo
+}
+
+// A LoggerAppenderInfo describes an appender associated with a logger.
+type LoggerAppenderInfo struct {
+	// Name defines the appender name.
+	Name string
+	// Origin defines the name of the logger that declares this appender: either the logger itself, or an additive
+	// chain of ancestors leads to it. An empty name defines the root logger.
+	Origin string
+}
+
+// Loggers returns how every logger of this handler is resolved, either configured or created by New, sorted by
+// name. The root logger, identified by an empty name, comes first.
+func (handler *handler) Loggers() []LoggerInfo {
+
+	list := []LoggerInfo{}
+
+	handler.loggers.Range(func(key, value interface{}) bool {
+		name := key.(string)
+
+		info, ok := handler.infos[name]
+		if !ok {
+			info = getDynamicLoggerInfo(handler.infos, name)
+		}
+
+		info.Appenders = append([]LoggerAppenderInfo{}, info.Appenders...)
+		list = append(list, info)
+
+		return true
+	})
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// getLoggerInfo returns how the logger identified by given name (an empty name for the root logger) is resolved
+// from given configuration.
+func getLoggerInfo(conf *Config, name string, level Level) LoggerInfo {
+
+	info := LoggerInfo{
+		Name:        name,
+		Configured:  true,
+		Level:       level,
+		LevelOrigin: getLevelOrigin(conf, name),
+		Appenders:   []LoggerAppenderInfo{},
+	}
+
+	origins := []string{""}
+	if name != "" {
+		info.Parent = getLoggerAncestors(conf, name)[0]
+		info.Additive = isChildLoggerAdditive(conf, name)
+		origins = getAppendersOrigins(conf, name)
+	}
+
+	// Since origins are sorted from the nearest to the farthest, an appender is declared by its nearest logger.
+	appenders := map[string]bool{}
+	for _, origin := range origins {
+		for _, appender := range getLoggerConfig(conf, origin).Appenders {
+			if !appenders[appender] {
+				appenders[appender] = true
+				info.Appenders = append(info.Appenders, LoggerAppenderInfo{
+					Name:   appender,
+					Origin: origin,
+				})
+			}
+		}
+	}
+
+	sort.Slice(info.Appenders, func(i, j int) bool {
+		return info.Appenders[i].Name < info.Appenders[j].Name
+	})
+
+	return info
+}
+
+// getDynamicLoggerInfo returns how the logger identified by given name, which has been created by New, is resolved:
+// it's a copy of its nearest configured ancestor.
+func getDynamicLoggerInfo(infos map[string]LoggerInfo, name string) LoggerInfo {
+
+	parent := ""
+	hierarchy := strings.Split(name, ".")
+	for cursor := len(hierarchy) - 1; cursor > 0; cursor-- {
+		current := strings.Join(hierarchy[0:cursor], ".")
+		_, ok := infos[current]
+		if ok {
+			parent = current
+			break
+		}
+	}
+
+	info := infos[parent]
+	info.Name = name
+	info.Configured = false
+	info.Parent = parent
+	info.Additive = true
+
+	return info
+}
@@ -0,0 +1,186 @@
+package soba
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+)
+
+// KeyPolicy defines how field keys are normalized.
+type KeyPolicy uint8
+
+const (
+	// PreserveKeyPolicy keeps field keys as they are defined.
+	PreserveKeyPolicy = KeyPolicy(iota)
+	// LowerKeyPolicy converts field keys to lowercase: "userID" becomes "userid".
+	LowerKeyPolicy
+	// SnakeCaseKeyPolicy converts field keys to snake case: "userID" becomes "user_id".
+	SnakeCaseKeyPolicy
+	// CamelCaseKeyPolicy converts field keys to camel case: "user_id" becomes "userId".
+	CamelCaseKeyPolicy
+)
+
+const (
+	strPreserveKeyPolicy  = "preserve"
+	strLowerKeyPolicy     = "lower"
+	strSnakeCaseKeyPolicy = "snake_case"
+	strCamelCaseKeyPolicy = "camel_case"
+)
+
+// ProtectedKeyPrefix is the prefix added to a field key that overwrites a reserved key: a key used by the schema of
+// the appender, such as its message key or one of its static fields.
+const ProtectedKeyPrefix = "fields."
+
+// Convert the KeyPolicy to a string.
+func (policy KeyPolicy) String() string {
+	switch policy {
+	case LowerKeyPolicy:
+		return strLowerKeyPolicy
+	case SnakeCaseKeyPolicy:
+		return strSnakeCaseKeyPolicy
+	case CamelCaseKeyPolicy:
+		return strCamelCaseKeyPolicy
+	default:
+		return strPreserveKeyPolicy
+	}
+}
+
+// Format normalizes given key using the policy.
+func (policy KeyPolicy) Format(key string) string {
+	switch policy {
+	case LowerKeyPolicy:
+		return strings.ToLower(key)
+	case SnakeCaseKeyPolicy:
+		return strings.Join(splitKeyWords(key), "_")
+	case CamelCaseKeyPolicy:
+		words := splitKeyWords(key)
+		for i := 1; i < len(words); i++ {
+			runes := []rune(words[i])
+			runes[0] = unicode.ToUpper(runes[0])
+			words[i] = string(runes)
+		}
+		return strings.Join(words, "")
+	default:
+		return key
+	}
+}
+
+// ParseKeyPolicy takes a string key policy and returns the key policy constant.
+// An empty string is considered as PreserveKeyPolicy.
+func ParseKeyPolicy(policy string) (KeyPolicy, bool) {
+	switch policy {
+	case strPreserveKeyPolicy, "":
+		return PreserveKeyPolicy, true
+	case strLowerKeyPolicy:
+		return LowerKeyPolicy, true
+	case strSnakeCaseKeyPolicy:
+		return SnakeCaseKeyPolicy, true
+	case strCamelCaseKeyPolicy:
+		return CamelCaseKeyPolicy, true
+	default:
+		return PreserveKeyPolicy, false
+	}
+}
+
+// IsKeyPolicyNameValid verify that a key policy name is allowed.
+func IsKeyPolicyNameValid(policy string) bool {
+	_, ok := ParseKeyPolicy(policy)
+	return ok
+}
+
+// splitKeyWords splits given key in lowercase words, using separators such as "_", "-" or " ", and case
+// transitions such as "userID" or "HTTPStatus".
+func splitKeyWords(key string) []string {
+	words := []string{}
+	runes := []rune(key)
+	current := []rune{}
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	for i, char := range runes {
+		switch {
+		case char == '_' || char == '-' || char == ' ':
+			flush()
+			continue
+
+		case unicode.IsUpper(char) && i > 0:
+			previous := runes[i-1]
+			hasNextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && hasNextLower) {
+				flush()
+			}
+		}
+
+		current = append(current, char)
+	}
+
+	flush()
+
+	return words
+}
+
+// keyFormatter normalizes field keys with a key policy.
+// If protect is enabled, a field key that overwrites a reserved key is prefixed when the entry is written, since
+// reserved keys depend on the schema of every appender.
+type keyFormatter struct {
+	policy  KeyPolicy
+	protect bool
+}
+
+// format normalizes given field key.
+func (keys keyFormatter) format(key string) string {
+	if keys.policy != PreserveKeyPolicy {
+		key = formatKey(keys.policy, key)
+	}
+
+	return key
+}
+
+// nested returns a key formatter for nested fields: reserved keys are only protected at the root level.
+func (keys keyFormatter) nested() keyFormatter {
+	return keyFormatter{
+		policy: keys.policy,
+	}
+}
+
+// maxFormattedKeys defines the maximum number of normalized keys kept in cache.
+const maxFormattedKeys = 4096
+
+// formattedKey identifies a normalized key in cache.
+type formattedKey struct {
+	policy KeyPolicy
+	key    string
+}
+
+var (
+	// formattedKeys is a cache of normalized keys, since field keys are usually constants.
+	formattedKeys = sync.Map{}
+	// formattedKeysSize is the number of normalized keys in cache.
+	formattedKeysSize = int64(0)
+)
+
+// formatKey normalizes given key with given policy, using a cache to reduce memory allocation pressure.
+func formatKey(policy KeyPolicy, key string) string {
+	id := formattedKey{policy: policy, key: key}
+
+	val, ok := formattedKeys.Load(id)
+	if ok {
+		return val.(string)
+	}
+
+	value := policy.Format(key)
+	if atomic.LoadInt64(&formattedKeysSize) < maxFormattedKeys {
+		_, loaded := formattedKeys.LoadOrStore(id, value)
+		if !loaded {
+			atomic.AddInt64(&formattedKeysSize, 1)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,215 @@
+package soba_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+// Test parsing of key policy.
+func TestKeyPolicy_Parse(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		expected soba.KeyPolicy
+		valid    bool
+	}{
+		{
+			input:    "",
+			expected: soba.PreserveKeyPolicy,
+			valid:    true,
+		},
+		{
+			input:    "preserve",
+			expected: soba.PreserveKeyPolicy,
+			valid:    true,
+		},
+		{
+			input:    "lower",
+			expected: soba.LowerKeyPolicy,
+			valid:    true,
+		},
+		{
+			input:    "snake_case",
+			expected: soba.SnakeCaseKeyPolicy,
+			valid:    true,
+		},
+		{
+			input:    "camel_case",
+			expected: soba.CamelCaseKeyPolicy,
+			valid:    true,
+		},
+		{
+			input:    "kebab_case",
+			expected: soba.PreserveKeyPolicy,
+			valid:    false,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		policy, valid := soba.ParseKeyPolicy(scenario.input)
+		if valid != scenario.valid {
+			t.Fatalf("Unexpected result for %s: %v should be %v", scenario.input, valid, scenario.valid)
+		}
+		if policy != scenario.expected {
+			t.Fatalf("Unexpected result for %s: %v should be %v", scenario.input, policy, scenario.expected)
+		}
+		if valid && scenario.input != "" && policy.String() != scenario.input {
+			t.Fatalf("Unexpected result for %s: %s should be %s", scenario.input, policy.String(), scenario.input)
+		}
+	}
+}
+
+// Test normalization of keys with a key policy.
+func TestKeyPolicy_Format(t *testing.T) {
+	scenarios := []struct {
+		input    string
+		policy   soba.KeyPolicy
+		expected string
+	}{
+		{
+			// Scenario #1
+			input:    "userID",
+			policy:   soba.PreserveKeyPolicy,
+			expected: "userID",
+		},
+		{
+			// Scenario #2
+			input:    "userID",
+			policy:   soba.LowerKeyPolicy,
+			expected: "userid",
+		},
+		{
+			// Scenario #3
+			input:    "userID",
+			policy:   soba.SnakeCaseKeyPolicy,
+			expected: "user_id",
+		},
+		{
+			// Scenario #4
+			input:    "HTTPStatusCode",
+			policy:   soba.SnakeCaseKeyPolicy,
+			expected: "http_status_code",
+		},
+		{
+			// Scenario #5
+			input:    "remote-addr v4",
+			policy:   soba.SnakeCaseKeyPolicy,
+			expected: "remote_addr_v4",
+		},
+		{
+			// Scenario #6
+			input:    "user_id",
+			policy:   soba.CamelCaseKeyPolicy,
+			expected: "userId",
+		},
+		{
+			// Scenario #7
+			input:    "HTTPStatusCode",
+			policy:   soba.CamelCaseKeyPolicy,
+			expected: "httpStatusCode",
+		},
+		{
+			// Scenario #8
+			input:    "ipv4Address",
+			policy:   soba.CamelCaseKeyPolicy,
+			expected: "ipv4Address",
+		},
+		{
+			// Scenario #9
+			input:    "message",
+			policy:   soba.SnakeCaseKeyPolicy,
+			expected: "message",
+		},
+	}
+
+	for i, scenario := range scenarios {
+		message := fmt.Sprintf("scenario #%d", (i + 1))
+		output := scenario.policy.Format(scenario.input)
+		if output != scenario.expected {
+			t.Fatalf("Unexpected result for %s: %s should be %s", message, output, scenario.expected)
+		}
+	}
+}
+
+// Test field keys normalization and protection using a handler.
+func TestKeyPolicy_Handler(t *testing.T) {
+	appender := NewTestAppender("keys-log")
+	defer CloseAppender(t, appender)
+
+	{
+		appender := &SchemaAppender{name: "keys-log", schema: soba.DefaultSchema}
+		conf := &soba.Config{
+			Root: soba.ConfigLogger{
+				Level: "info",
+				Appenders: []string{
+					"keys-log",
+				},
+			},
+			Keys: soba.ConfigKeys{
+				Policy:  "snake_case",
+				Protect: true,
+			},
+		}
+		registry := NewTestRegistry(t, conf, appender)
+
+		handler, err := soba.CreateWithRegistry(conf, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		logger := handler.New("keys.snake").With(soba.String("userID", "u1"))
+		logger.Info("User updated", soba.String("user_id", "u2"), soba.String("Message", "overwritten"),
+			soba.Group("HTTPRequest", soba.String("RemoteAddr", "10.0.7.23"), soba.String("level", "nested")))
+
+		prefix := `{"logger":"keys.snake","time":`
+		suffix := fmt.Sprint(
+			`"level":"info","message":"User updated",`,
+			`"user_id":"u2","fields.message":"overwritten",`,
+			`"http_request":{"remote_addr":"10.0.7.23","level":"nested"}}`,
+			"\n",
+		)
+
+		if len(appender.entries) != 1 {
+			t.Fatalf("Unexpected number of entries for appender: %d should be %d", len(appender.entries), 1)
+		}
+		if !strings.HasPrefix(appender.entries[0], prefix) || !strings.HasSuffix(appender.entries[0], suffix) {
+			t.Fatalf("Unexpected log message #1: '%s' should be '%s...%s'", appender.entries[0], prefix, suffix)
+		}
+	}
+	{
+		conf := &soba.Config{
+			Root: soba.ConfigLogger{
+				Level: "info",
+				Appenders: []string{
+					"keys-log",
+				},
+			},
+		}
+		registry := NewTestRegistry(t, conf, appender)
+
+		handler, err := soba.CreateWithRegistry(conf, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		logger := handler.New("keys.preserve")
+		logger.Info("User updated", soba.String("userID", "u1"), soba.String("userid", "u2"))
+
+		expected := fmt.Sprint(
+			`{"logger":"keys.preserve","level":"info","message":"User updated",`,
+			`"userID":"u1","userid":"u2"}`,
+			"\n",
+		)
+
+		if appender.Size() != 1 {
+			t.Fatalf("Unexpected number of entries for appender: %d should be %d", appender.Size(), 1)
+		}
+		if appender.Log(0) != expected {
+			t.Fatalf("Unexpected log message #1: '%s' should be '%s'", appender.Log(0), expected)
+		}
+
+		appender.Clear()
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // IsLoggerNameValid verify that a Logger name has a valid format.
@@ -16,6 +19,11 @@ type Logger struct {
 	level     Level
 	appenders []Appender
 	fields    []Field
+	keys      keyFormatter
+	redactor  *Redactor
+	context   *fieldContext
+	// closed is set once the handler of this logger is shut down.
+	closed *uint32
 }
 
 // New creates a new Logger using given name.
@@ -56,9 +64,38 @@ func (logger Logger) Level() Level {
 	return logger.level
 }
 
+// Enabled returns if this logger writes entries with given level.
+func (logger Logger) Enabled(level Level) bool {
+	switch level {
+	case DebugLevel, InfoLevel, WarnLevel, ErrorLevel:
+		return logger.level >= level && logger.level != NoLevel
+	default:
+		return false
+	}
+}
+
+// Check returns a CheckedEntry if this logger writes entries with given level, or nil otherwise.
+// It allows to compute expensive fields only if the entry will be written:
+//
+//	if entry := logger.Check(soba.DebugLevel, "Cache updated"); entry != nil {
+//		entry.Write(soba.Strings("keys", cache.Keys()))
+//	}
+func (logger Logger) Check(level Level, message string) *CheckedEntry {
+	if !logger.Enabled(level) {
+		return nil
+	}
+
+	entry := checkedEntryPool.Get().(*CheckedEntry)
+	entry.logger = logger
+	entry.level = level
+	entry.message = message
+
+	return entry
+}
+
 // Debug logs a message at DebugLevel.
 func (logger Logger) Debug(message string, fields ...Field) {
-	if logger.level < DebugLevel || logger.level == NoLevel {
+	if !logger.Enabled(DebugLevel) {
 		return
 	}
 	logger.write(DebugLevel, message, fields)
@@ -66,7 +103,7 @@ func (logger Logger) Debug(message string, fields ...Field) {
 
 // Info logs a message at InfoLevel.
 func (logger Logger) Info(message string, fields ...Field) {
-	if logger.level < InfoLevel || logger.level == NoLevel {
+	if !logger.Enabled(InfoLevel) {
 		return
 	}
 	logger.write(InfoLevel, message, fields)
@@ -74,7 +111,7 @@ func (logger Logger) Info(message string, fields ...Field) {
 
 // Warn logs a message at WarnLevel.
 func (logger Logger) Warn(message string, fields ...Field) {
-	if logger.level < WarnLevel || logger.level == NoLevel {
+	if !logger.Enabled(WarnLevel) {
 		return
 	}
 	logger.write(WarnLevel, message, fields)
@@ -82,21 +119,50 @@ func (logger Logger) Warn(message string, fields ...Field) {
 
 // Error logs a message at ErrorLevel.
 func (logger Logger) Error(message string, fields ...Field) {
-	if logger.level < ErrorLevel || logger.level == NoLevel {
+	if !logger.Enabled(ErrorLevel) {
 		return
 	}
 	logger.write(ErrorLevel, message, fields)
 }
 
+// WithRedactor returns a copy of this logger that masks or drops sensitive data using given redactor.
+func (logger Logger) WithRedactor(redactor *Redactor) Logger {
+	other := logger.copy()
+	other.redactor = redactor
+	other.context = newFieldContext(other.fields, other.keys, other.redactor)
+	return other
+}
+
 // With appends given structured fields to it.
+//
+// These fields are encoded once per type of encoder, and then reused by every entry: they should not be
+// modified afterward. A lazy field is still computed for every entry.
+//
+// However, a field given to a log method still overwrites a field with the same name.
 func (logger Logger) With(fields ...Field) Logger {
 	other := logger.copy()
 	other.fields = append(other.fields, fields...)
+	other.context = newFieldContext(other.fields, other.keys, other.redactor)
 	return other
 }
 
 func (logger Logger) write(level Level, message string, fields []Field) {
-	entry := NewEntry(logger.name, level, message, logger.fields, fields)
+	logger.writeAt(time.Now().Unix(), level, message, fields)
+}
+
+// writeAt is like write, but the entry timestamp is given, as a number of seconds since the Unix epoch.
+func (logger Logger) writeAt(unix int64, level Level, message string, fields []Field) {
+	if logger.closed != nil && atomic.LoadUint32(logger.closed) == 1 {
+		return
+	}
+
+	var entry *Entry
+	if logger.context != nil && logger.context.accepts(fields) {
+		entry = newEntry(logger.name, unix, level, message, logger.keys, logger.redactor, [][]Field{fields})
+		entry.context = logger.context
+	} else {
+		entry = newEntry(logger.name, unix, level, message, logger.keys, logger.redactor, [][]Field{logger.fields, fields})
+	}
 	defer entry.Flush()
 	for i := range logger.appenders {
 		logger.appenders[i].Write(entry)
@@ -120,9 +186,150 @@ func (logger Logger) copy() Logger {
 	other.name = logger.name
 	other.level = logger.level
 	other.appenders = logger.appenders
+	other.keys = logger.keys
+	other.redactor = logger.redactor
+	other.context = logger.context
+	other.closed = logger.closed
 
 	other.fields = make([]Field, len(logger.fields), cap(logger.fields))
 	copy(other.fields, logger.fields)
 
 	return other
 }
+
+// fieldContext contains the fields attached to a logger with With, and their pre-encoded fragments for every
+// type of encoder.
+type fieldContext struct {
+	once      sync.Once
+	fields    []Field
+	keys      keyFormatter
+	redactor  *Redactor
+	set       *fieldSet
+	reusable  bool
+	fragments sync.Map
+}
+
+// newFieldContext creates a new fieldContext with given fields, key formatter and redactor.
+func newFieldContext(fields []Field, keys keyFormatter, redactor *Redactor) *fieldContext {
+	return &fieldContext{
+		fields:   fields,
+		keys:     keys,
+		redactor: redactor,
+	}
+}
+
+// init removes the duplicate fields of the context, once.
+// The fragments can be reused only if the context doesn't end with an opened namespace, since the following
+// fields would be nested in it, and if it has no lazy field, since it's computed for every entry.
+func (context *fieldContext) init() {
+	context.once.Do(func() {
+		context.set = newFieldSet(len(context.fields), context.keys)
+		cursor := context.set
+		for i := range context.fields {
+			cursor = cursor.add(context.fields[i])
+		}
+		context.reusable = cursor == context.set && !context.set.isLazy()
+	})
+}
+
+// accepts returns if given fields can be written after the fragment of the context: none of them should
+// overwrite a field of the context.
+func (context *fieldContext) accepts(fields []Field) bool {
+	context.init()
+
+	if !context.reusable {
+		return false
+	}
+
+	for i := range fields {
+		_, ok := context.set.indexes[context.keys.format(fields[i].name)]
+		if ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// list returns the fields of the context, without duplicate.
+func (context *fieldContext) list() []Field {
+	context.init()
+	return context.set.list
+}
+
+// fragmentKey identifies a pre-encoded fragment of a context: it depends on the encoder type, and on the field
+// prefix and the reserved keys of the schema.
+type fragmentKey struct {
+	kind   string
+	schema *Schema
+}
+
+// fragment returns the fields of the context encoded with given encoder type and given schema.
+func (context *fieldContext) fragment(encoder FragmentEncoder, schema *Schema) []byte {
+	kind := fragmentKey{
+		kind:   encoder.FragmentType(),
+		schema: schema,
+	}
+
+	value, ok := context.fragments.Load(kind)
+	if ok {
+		return value.([]byte)
+	}
+
+	fragment := encoder.EncodeFragment(func(encoder Encoder) {
+		fields := context.redactor.prefixedEncoder(encoder, schema.FieldPrefix)
+		for _, field := range context.list() {
+			schema.writeField(context.redactor.fieldEncoder(fields, encoder, field), field, context.keys.protect)
+		}
+	})
+
+	value, _ = context.fragments.LoadOrStore(kind, fragment)
+
+	return value.([]byte)
+}
+
+// A CheckedEntry is an entry that will be written by its logger, obtained with Logger.Check.
+// It must not be used after Write.
+type CheckedEntry struct {
+	logger  Logger
+	level   Level
+	message string
+	fields  []Field
+}
+
+// With adds given structured fields to the entry.
+// It's a no-op if the entry is nil.
+func (entry *CheckedEntry) With(fields ...Field) *CheckedEntry {
+	if entry == nil {
+		return nil
+	}
+
+	entry.fields = append(entry.fields, fields...)
+
+	return entry
+}
+
+// Write writes the entry, with given structured fields, using its logger.
+// It's a no-op if the entry is nil.
+func (entry *CheckedEntry) Write(fields ...Field) {
+	if entry == nil {
+		return
+	}
+
+	entry.fields = append(entry.fields, fields...)
+	entry.logger.write(entry.level, entry.message, entry.fields)
+
+	entry.logger = Logger{}
+	entry.message = ""
+	entry.fields = entry.fields[:0]
+	checkedEntryPool.Put(entry)
+}
+
+// checkedEntryPool is a pool of CheckedEntry.
+var checkedEntryPool = sync.Pool{
+	New: func() interface{} {
+		return &CheckedEntry{
+			fields: make([]Field, 0, 16),
+		}
+	},
+}
@@ -1,11 +1,16 @@
 package soba_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/novln/soba"
+	"github.com/novln/soba/encoder/json"
 )
 
 // Test logger name format.
@@ -377,3 +382,390 @@ func TestLogger_WithFields(t *testing.T) {
 		t.Fatalf("Unexpected log message #3: '%s' should be '%s'", appender.Log(2), expected3)
 	}
 }
+
+// Test logger with a namespace in custom fields.
+func TestLogger_WithNamespace(t *testing.T) {
+	appender := NewTestAppender("foobar")
+	defer CloseAppender(t, appender)
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender})
+
+	logger = logger.With(soba.String("module", "xyz"), soba.Namespace("db"), soba.String("table", "users"))
+	logger.Info("Random message 1", soba.Int("id", 1))
+	logger.Info("Random message 2", soba.String("table", "groups"))
+
+	if appender.Size() != 2 {
+		t.Fatalf("Unexpected number of entries for appender: %d should be %d", appender.Size(), 2)
+	}
+
+	expected1 := fmt.Sprint(
+		`{"logger":"foobar","level":"info","message":"Random message 1",`,
+		`"module":"xyz","db":{"table":"users","id":1}}`,
+		"\n",
+	)
+	expected2 := fmt.Sprint(
+		`{"logger":"foobar","level":"info","message":"Random message 2",`,
+		`"module":"xyz","db":{"table":"groups"}}`,
+		"\n",
+	)
+
+	if appender.Log(0) != expected1 {
+		t.Fatalf("Unexpected log message #1: '%s' should be '%s'", appender.Log(0), expected1)
+	}
+	if appender.Log(1) != expected2 {
+		t.Fatalf("Unexpected log message #2: '%s' should be '%s'", appender.Log(1), expected2)
+	}
+}
+
+// Test logger enabled levels.
+func TestLogger_Enabled(t *testing.T) {
+	scenarios := []struct {
+		level    soba.Level
+		expected map[soba.Level]bool
+	}{
+		{
+			// Scenario #1
+			level: soba.DebugLevel,
+			expected: map[soba.Level]bool{
+				soba.DebugLevel: true, soba.InfoLevel: true, soba.WarnLevel: true, soba.ErrorLevel: true,
+			},
+		},
+		{
+			// Scenario #2
+			level: soba.WarnLevel,
+			expected: map[soba.Level]bool{
+				soba.DebugLevel: false, soba.InfoLevel: false, soba.WarnLevel: true, soba.ErrorLevel: true,
+			},
+		},
+		{
+			// Scenario #3
+			level: soba.NoLevel,
+			expected: map[soba.Level]bool{
+				soba.DebugLevel: false, soba.InfoLevel: false, soba.WarnLevel: false, soba.ErrorLevel: false,
+			},
+		},
+		{
+			// Scenario #4
+			level: soba.UnknownLevel,
+			expected: map[soba.Level]bool{
+				soba.DebugLevel: false, soba.InfoLevel: false, soba.WarnLevel: false, soba.ErrorLevel: false,
+			},
+		},
+	}
+
+	for i, scenario := range scenarios {
+		message := fmt.Sprintf("scenario #%d", (i + 1))
+		logger := soba.NewLogger("foobar", scenario.level, []soba.Appender{})
+
+		for level, expected := range scenario.expected {
+			if logger.Enabled(level) != expected {
+				t.Fatalf("Unexpected enabled level %s for %s: %t should be %t", level, message, !expected, expected)
+			}
+		}
+
+		if logger.Enabled(soba.NoLevel) || logger.Enabled(soba.UnknownLevel) {
+			t.Fatalf("Unexpected enabled level for %s", message)
+		}
+	}
+}
+
+// Test logger with checked entries.
+func TestLogger_Check(t *testing.T) {
+	appender := NewTestAppender("foobar")
+	defer CloseAppender(t, appender)
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender})
+	logger = logger.With(soba.String("service", "api"))
+
+	entry := logger.Check(soba.DebugLevel, "Cache updated")
+	if entry != nil {
+		t.Fatal("Unexpected checked entry for debug level")
+	}
+
+	entry.With(soba.Int("size", 42)).Write(soba.Bool("full", false))
+
+	entry = logger.Check(soba.WarnLevel, "Cache is almost full")
+	if entry == nil {
+		t.Fatal("A checked entry was expected for warn level")
+	}
+
+	entry.With(soba.Int("size", 42)).Write(soba.Bool("full", false))
+
+	if appender.Size() != 1 {
+		t.Fatalf("Unexpected number of entries for appender: %d should be %d", appender.Size(), 1)
+	}
+
+	expected := fmt.Sprint(
+		`{"logger":"foobar","level":"warning","message":"Cache is almost full",`,
+		`"service":"api","size":42,"full":false}`,
+		"\n",
+	)
+
+	if appender.Log(0) != expected {
+		t.Fatalf("Unexpected log message: '%s' should be '%s'", appender.Log(0), expected)
+	}
+}
+
+// Test logger with lazy fields.
+func TestLogger_Lazy(t *testing.T) {
+	appender := NewTestAppender("foobar")
+	defer CloseAppender(t, appender)
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender, appender})
+
+	calls := 0
+	field := func() soba.Field {
+		return soba.Lazy("keys", func() soba.Field {
+			calls++
+			return soba.Strings("", []string{"foo", "bar"})
+		})
+	}
+
+	logger.Debug("Cache updated", field())
+	if calls != 0 {
+		t.Fatalf("Unexpected number of calls: %d should be %d", calls, 0)
+	}
+
+	logger.Info("Cache updated", field())
+	if calls != 1 {
+		t.Fatalf("Unexpected number of calls: %d should be %d", calls, 1)
+	}
+
+	expected := fmt.Sprint(
+		`{"logger":"foobar","level":"info","message":"Cache updated","keys":["foo","bar"]}`,
+		"\n",
+	)
+
+	if appender.Size() != 2 {
+		t.Fatalf("Unexpected number of entries for appender: %d should be %d", appender.Size(), 2)
+	}
+	if appender.Log(0) != expected {
+		t.Fatalf("Unexpected log message #1: '%s' should be '%s'", appender.Log(0), expected)
+	}
+	if appender.Log(1) != expected {
+		t.Fatalf("Unexpected log message #2: '%s' should be '%s'", appender.Log(1), expected)
+	}
+}
+
+// Test that a lazy field attached with With is computed once for every entry, rather than once for the logger.
+func TestLogger_LazyContext(t *testing.T) {
+	appender := &EncoderAppender{}
+
+	calls := 0
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender, appender})
+	logger = logger.With(soba.String("service", "api"), soba.Lazy("calls", func() soba.Field {
+		calls++
+		return soba.Int("", calls)
+	}))
+
+	for i := 1; i <= 3; i++ {
+		logger.Info("Request received")
+		if calls != i {
+			t.Fatalf("Unexpected number of calls: %d should be %d", calls, i)
+		}
+
+		expected := fmt.Sprintf(`"message":"Request received","service":"api","calls":%d}`, i)
+		for _, line := range appender.expected[2*(i-1):] {
+			if !strings.Contains(line, expected) {
+				t.Fatalf("Unexpected log message: '%s' should contain '%s'", line, expected)
+			}
+		}
+	}
+}
+
+// DiscardAppender is an appender that encodes entries and discards them, for benchmark.
+type DiscardAppender struct{}
+
+func (DiscardAppender) Name() string {
+	return "discard"
+}
+
+func (DiscardAppender) Close() error {
+	return nil
+}
+
+func (DiscardAppender) Write(entry *soba.Entry) {
+	encoder := json.NewEncoder()
+	defer encoder.Close()
+	soba.WriteEntry(entry, encoder)
+}
+
+// Ensure DiscardAppender implements Appender interface at compile time.
+var _ soba.Appender = DiscardAppender{}
+
+// logScalarFields writes an entry with scalar fields using given logger.
+func logScalarFields(logger soba.Logger) {
+	logger.Info("Request received",
+		soba.String("method", "GET"),
+		soba.Int("status", 200),
+		soba.Int64("size", 2048),
+		soba.Uint32("port", 8080),
+		soba.Float64("ratio", 0.75),
+		soba.Bool("cached", true),
+		soba.Duration("elapsed", 3*time.Millisecond),
+		soba.Time("date", time.Date(2019, 4, 20, 9, 53, 13, 0, time.UTC)),
+	)
+}
+
+// NewScalarFieldsLogger creates a logger from a handler, with context fields and a redactor, that writes on a file
+// appender. The returned callback closes the handler and removes its file.
+func NewScalarFieldsLogger(t testing.TB) (soba.Logger, func()) {
+	t.Helper()
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level:     "info",
+			Appenders: []string{"file-log"},
+		},
+		Appenders: map[string]soba.ConfigAppender{
+			"file-log": {
+				Type:     soba.FileAppenderType,
+				Path:     "testdata/logs/allocation.log",
+				MaxBytes: 1 << 20,
+			},
+		},
+		Loggers: map[string]soba.ConfigLogger{},
+		Redact: soba.ConfigRedact{
+			Keys: []soba.ConfigRedactKey{{Pattern: "token"}},
+		},
+	}
+
+	handler, err := soba.CreateWithConfig(conf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	logger := handler.New("foobar").With(soba.String("service", "api"), soba.String("token", "secret"))
+
+	return logger, func() {
+		err := handler.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		err = os.Remove("testdata/logs/allocation.log")
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+}
+
+// Benchmark allocation of scalar fields with a disabled and an enabled logger, and with a logger from a handler.
+func BenchmarkLogger_ScalarFields(b *testing.B) {
+	b.Run("disabled", func(b *testing.B) {
+		logger := soba.NewLogger("foobar", soba.WarnLevel, []soba.Appender{DiscardAppender{}})
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logScalarFields(logger)
+		}
+	})
+	b.Run("enabled", func(b *testing.B) {
+		logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{DiscardAppender{}})
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logScalarFields(logger)
+		}
+	})
+	b.Run("handler", func(b *testing.B) {
+		logger, closer := NewScalarFieldsLogger(b)
+		defer closer()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logScalarFields(logger)
+		}
+	})
+}
+
+// Test scalar fields are written without memory allocation.
+func TestLogger_ScalarFieldsAllocation(t *testing.T) {
+	if raceEnabled {
+		t.Skip("The race detector adds memory allocations")
+	}
+
+	handler, closer := NewScalarFieldsLogger(t)
+	defer closer()
+
+	loggers := map[string]soba.Logger{
+		"disabled": soba.NewLogger("foobar", soba.WarnLevel, []soba.Appender{DiscardAppender{}}),
+		"enabled":  soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{DiscardAppender{}}),
+		"handler":  handler,
+	}
+
+	for name, logger := range loggers {
+		allocs := testing.AllocsPerRun(100, func() {
+			logScalarFields(logger)
+		})
+		if allocs != 0 {
+			t.Fatalf("Unexpected number of allocations with %s logger: %f should be %d", name, allocs, 0)
+		}
+	}
+}
+
+// Benchmark writing an entry with a logger that has many context fields.
+func BenchmarkLogger_ContextFields(b *testing.B) {
+	fields := []soba.Field{}
+	for i := 0; i < 20; i++ {
+		fields = append(fields, soba.String(fmt.Sprint("key", i), fmt.Sprint("value", i)))
+	}
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{DiscardAppender{}}).With(fields...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("Request received", soba.String("method", "GET"), soba.Int("status", 200))
+	}
+}
+
+// Test logger with context fields written by an encoder with and without pre-encoded fragments.
+func TestLogger_WithContextFields(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	appender := NewTestAppender("foobar")
+	console := soba.NewConsoleAppender("console", buffer)
+
+	redactor, err := soba.NewRedactor(soba.ConfigRedact{
+		Keys: []soba.ConfigRedactKey{{Pattern: "token"}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender, console})
+	logger = logger.WithRedactor(redactor).With(
+		soba.String("service", "api"), soba.String("token", "secret"), soba.Int("status", 0),
+	)
+	other := logger.With(soba.Namespace("request"), soba.String("method", "GET"))
+
+	logger.Info("Request received", soba.String("method", "GET"))
+	logger.Info("Request sent", soba.Int("status", 200), soba.String("method", "POST"))
+	other.Info("Request received", soba.String("method", "PUT"), soba.Int("status", 204))
+	logger.Info("Request received", soba.String("method", "DELETE"))
+
+	expected := []string{
+		`"message":"Request received","service":"api","token":"[REDACTED]","status":0,"method":"GET"}`,
+		`"message":"Request sent","service":"api","token":"[REDACTED]","status":200,"method":"POST"}`,
+		fmt.Sprint(
+			`"message":"Request received","service":"api","token":"[REDACTED]","status":0,`,
+			`"request":{"method":"PUT","status":204}}`,
+		),
+		`"message":"Request received","service":"api","token":"[REDACTED]","status":0,"method":"DELETE"}`,
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != len(expected) || appender.Size() != len(expected) {
+		t.Fatalf("Unexpected number of entries: %d should be %d", len(lines), len(expected))
+	}
+
+	for i := range expected {
+		if !strings.HasSuffix(lines[i], expected[i]) {
+			t.Fatalf("Unexpected log message #%d: '%s' should end with '%s'", i+1, lines[i], expected[i])
+		}
+		// TestAppender doesn't apply the redactor.
+		raw := strings.Replace(expected[i], "[REDACTED]", "secret", 1)
+		if !strings.HasSuffix(appender.Log(i), raw+"\n") {
+			t.Fatalf("Unexpected log message #%d: '%s' should end with '%s'", i+1, appender.Log(i), raw)
+		}
+	}
+}
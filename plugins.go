@@ -1,34 +1,186 @@
 package soba
 
 import (
+	"regexp"
+	"sort"
 	"sync"
 
 	"github.com/pkg/errors"
 )
 
-// These global variables are only used to provide extensibility with external appenders.
-// If you think of a better solution, please submit a pull request, because I hate global variables.
-var (
-	// plMutex is a mutex to manage concurrent access when registering an appender.
-	plMutex = sync.Mutex{}
-	// plAppenders is a list of external appenders identified by their name.
-	plAppenders = map[string]Appender{}
-)
+// An AppenderFactory creates a new appender with given name and configuration.
+// A factory is called once for every appender of a handler, so two handlers never share the same instance.
+type AppenderFactory func(name string, conf ConfigAppender) (Appender, error)
 
-// RegisterAppenders registers given external appenders to be accessible for loggers.
-func RegisterAppenders(appenders ...Appender) error {
-	plMutex.Lock()
-	defer plMutex.Unlock()
+// An AppenderValidator verifies the configuration of a custom appender type, such as its options, when the
+// configuration is validated. It allows to report a precise error before any appender is created.
+// A ConfigIssue, or a MultiError of ConfigIssue, such as the error returned by ConfigOptions.Decode, is located with
+// its path relative to the options, like "appenders.events.options.topic".
+type AppenderValidator func(name string, conf ConfigAppender) error
 
-	for _, appender := range appenders {
+// IsAppenderTypeValid verify that an Appender type has a valid format.
+var IsAppenderTypeValid = regexp.MustCompile(`^[a-z]+[a-z._0-9-]*[a-z0-9]+$`).MatchString
 
-		if !IsAppenderNameValid(appender.Name()) {
-			return errors.Errorf("name is invalid for appender: %s", appender.Name())
-		}
+// A Registry contains the appender types available to a handler, such as "console" or "file".
+// A custom appender type is registered with a factory, and then configured like a built-in one with its type.
+// All methods are safe for concurrent use.
+type Registry struct {
+	mutex      sync.RWMutex
+	factories  map[string]AppenderFactory
+	validators map[string]AppenderValidator
+}
 
-		plAppenders[appender.Name()] = appender
+// NewRegistry creates a new Registry with the built-in appender types.
+// It allows to scope custom appender types to a handler, using CreateWithRegistry.
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: map[string]AppenderFactory{
+			ConsoleAppenderType: newConsoleAppenderFromConfig,
+			FileAppenderType:    newFileAppenderFromConfig,
+		},
+		validators: map[string]AppenderValidator{},
+	}
+}
 
+// RegisterAppenderType registers a custom appender type, whose appenders are created with given factory.
+func (registry *Registry) RegisterAppenderType(kind string, factory AppenderFactory) error {
+	if !IsAppenderTypeValid(kind) {
+		return errors.Errorf("type is invalid for appender factory: %s", kind)
 	}
+	if factory == nil {
+		return errors.Errorf("factory is required for appender type: %s", kind)
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	_, ok := registry.factories[kind]
+	if ok {
+		return errors.Errorf("appender type is already registered: %s", kind)
+	}
+
+	registry.factories[kind] = factory
 
 	return nil
 }
+
+// RegisterAppenderValidator registers a validation hook for a custom appender type, which is called for every
+// appender of this type when the configuration is validated.
+func (registry *Registry) RegisterAppenderValidator(kind string, validator AppenderValidator) error {
+	if validator == nil {
+		return errors.Errorf("validator is required for appender type: %s", kind)
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	_, ok := registry.factories[kind]
+	if !ok || kind == ConsoleAppenderType || kind == FileAppenderType {
+		return errors.Errorf("appender type is not a registered custom type: %s", kind)
+	}
+
+	_, ok = registry.validators[kind]
+	if ok {
+		return errors.Errorf("appender validator is already registered: %s", kind)
+	}
+
+	registry.validators[kind] = validator
+
+	return nil
+}
+
+// IsAppenderTypeRegistered verifies if given appender type is available.
+func (registry *Registry) IsAppenderTypeRegistered(kind string) bool {
+	_, ok := registry.getFactory(kind)
+	return ok
+}
+
+// NewAppender creates a new Appender from given configuration, using the factory of its type.
+func (registry *Registry) NewAppender(name string, conf ConfigAppender) (Appender, error) {
+	validator := newConfigValidator(nil)
+	validateAppenderConfig(name, conf, registry, validator)
+	err := validator.err()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create appender for %s", name)
+	}
+
+	factory, ok := registry.getFactory(conf.Type)
+	if !ok {
+		// Should be handled by validateAppenderConfig function.
+		return nil, errors.Errorf("unknown appender type for %s: %s", name, conf.Type)
+	}
+
+	appender, err := factory(name, conf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create appender for %s", name)
+	}
+	if appender == nil {
+		return nil, errors.Errorf("factory returned no appender for %s: %s", name, conf.Type)
+	}
+
+	return appender, nil
+}
+
+// validate executes the validation hook of given appender configuration type, if any.
+func (registry *Registry) validate(name string, conf ConfigAppender) error {
+	registry.mutex.RLock()
+	validator, ok := registry.validators[conf.Type]
+	registry.mutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return validator(name, conf)
+}
+
+// types returns the registered appender types, in order.
+func (registry *Registry) types() []string {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	list := make([]string, 0, len(registry.factories))
+	for kind := range registry.factories {
+		list = append(list, kind)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+func (registry *Registry) getFactory(kind string) (AppenderFactory, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	factory, ok := registry.factories[kind]
+	return factory, ok
+}
+
+// This global variable is only used to provide extensibility with external appender types for handlers created
+// without a registry, such as with Load or Create.
+var defaultRegistry = NewRegistry()
+
+// RegisterAppenderType registers a custom appender type for every handler created without a registry.
+// Then, it could be configured like a built-in one:
+//
+//	appenders:
+//	  events:
+//	    type: kafka
+//	    options:
+//	      topic: events
+func RegisterAppenderType(kind string, factory AppenderFactory) error {
+	return defaultRegistry.RegisterAppenderType(kind, factory)
+}
+
+// RegisterAppenderValidator registers a validation hook for a custom appender type of every handler created without
+// a registry. It must be registered after its appender type:
+//
+//	err := soba.RegisterAppenderType("kafka", NewKafkaAppender)
+//	...
+//	err = soba.RegisterAppenderValidator("kafka", func(name string, conf soba.ConfigAppender) error {
+//		options := KafkaOptions{}
+//		return conf.Options.Decode(&options)
+//	})
+func RegisterAppenderValidator(kind string, validator AppenderValidator) error {
+	return defaultRegistry.RegisterAppenderValidator(kind, validator)
+}
@@ -1,35 +1,446 @@
 package soba_test
 
 import (
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/novln/soba"
 )
 
-// Test registration of appenders.
-func TestPlugins_RegisterAppenders(t *testing.T) {
-	apiAppender := NewTestAppender("api-log")
-	defer CloseAppender(t, apiAppender)
+// PluginAppender is a custom appender created by a factory.
+type PluginAppender struct {
+	*TestAppender
+	closed bool
+}
+
+func (appender *PluginAppender) Close() error {
+	appender.closed = true
+	return nil
+}
+
+// Test registration of appender types.
+func TestPlugins_RegisterAppenderType(t *testing.T) {
+	factory := func(name string, conf soba.ConfigAppender) (soba.Appender, error) {
+		return NewTestAppender(name), nil
+	}
+
+	scenarios := []struct {
+		kind    string
+		factory soba.AppenderFactory
+		valid   bool
+	}{
+		{kind: "kafka", factory: factory, valid: true},
+		{kind: "cloud.watch", factory: factory, valid: true},
+		{kind: "console", factory: factory, valid: false},
+		{kind: "file", factory: factory, valid: false},
+		{kind: "Kafka", factory: factory, valid: false},
+		{kind: "", factory: factory, valid: false},
+		{kind: "syslog", factory: nil, valid: false},
+	}
+
+	for _, scenario := range scenarios {
+		registry := soba.NewRegistry()
+		err := registry.RegisterAppenderType(scenario.kind, scenario.factory)
+		if scenario.valid && err != nil {
+			t.Fatalf("Unexpected error for %s: %+v", scenario.kind, err)
+		}
+		if !scenario.valid && err == nil {
+			t.Fatalf("An error was expected for %s", scenario.kind)
+		}
+		if registry.IsAppenderTypeRegistered(scenario.kind) != (scenario.valid || scenario.kind == "console" ||
+			scenario.kind == "file") {
+			t.Fatalf("Unexpected registration for %s", scenario.kind)
+		}
+	}
+
+	registry := soba.NewRegistry()
+	err := registry.RegisterAppenderType("kafka", factory)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	err = registry.RegisterAppenderType("kafka", factory)
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+}
+
+// Test creation of appenders with a custom appender type.
+// nolint: gocyclo
+func TestPlugins_NewAppender(t *testing.T) {
+	appenders := []*PluginAppender{}
+
+	registry := soba.NewRegistry()
+	err := registry.RegisterAppenderType("plugin", func(name string, conf soba.ConfigAppender) (soba.Appender, error) {
+		if conf.Path == "" {
+			return nil, fmt.Errorf("path is required")
+		}
+		appender := &PluginAppender{TestAppender: NewTestAppender(name)}
+		appenders = append(appenders, appender)
+		return appender, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+	err = registry.RegisterAppenderType("broken", func(name string, conf soba.ConfigAppender) (soba.Appender, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	create := func(conf soba.ConfigAppender) (soba.Handler, error) {
+		return soba.CreateWithRegistry(&soba.Config{
+			Root: soba.ConfigLogger{
+				Level: "info",
+				Appenders: []string{
+					"plugin-log",
+				},
+			},
+			Appenders: map[string]soba.ConfigAppender{
+				"plugin-log": conf,
+			},
+		}, registry)
+	}
+
+	{
+		_, err := create(soba.ConfigAppender{Type: "plugin"})
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+
+		_, err = create(soba.ConfigAppender{Type: "broken"})
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+
+		_, err = create(soba.ConfigAppender{Type: "unknown"})
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+
+		_, err = soba.CreateWithConfig(&soba.Config{
+			Root: soba.ConfigLogger{
+				Level: "info",
+				Appenders: []string{
+					"plugin-log",
+				},
+			},
+			Appenders: map[string]soba.ConfigAppender{
+				"plugin-log": {
+					Type: "plugin",
+					Path: "events",
+				},
+			},
+		})
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+
+		_, err = soba.CreateWithRegistry(soba.NewDefaultConfig(), nil)
+		if err == nil {
+			t.Fatal("An error was expected")
+		}
+	}
+	{
+		handler1, err := create(soba.ConfigAppender{Type: "plugin", Path: "events"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		handler2, err := create(soba.ConfigAppender{Type: "plugin", Path: "events"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		if len(appenders) != 2 {
+			t.Fatalf("Unexpected number of appenders: %d should be %d", len(appenders), 2)
+		}
+
+		handler1.New("foo").Info("Hello from handler #1")
+		handler2.New("bar").Info("Hello from handler #2")
+
+		err = handler1.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if !appenders[0].closed {
+			t.Fatal("Appender of handler #1 should be closed")
+		}
+		if appenders[1].closed {
+			t.Fatal("Appender of handler #2 should not be closed")
+		}
+
+		handler2.New("bar").Info("Hello again from handler #2")
+
+		if appenders[0].Size() != 1 {
+			t.Fatalf("Unexpected number of entries: %d should be %d", appenders[0].Size(), 1)
+		}
+		if appenders[1].Size() != 2 {
+			t.Fatalf("Unexpected number of entries: %d should be %d", appenders[1].Size(), 2)
+		}
+
+		err = handler2.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if !appenders[1].closed {
+			t.Fatal("Appender of handler #2 should be closed")
+		}
+	}
+}
+
+// Test registration of an appender type for handlers created without a registry.
+func TestPlugins_DefaultRegistry(t *testing.T) {
+	appender := NewTestAppender("default-plugin-log")
+
+	err := soba.RegisterAppenderType("default-plugin", func(name string, conf soba.ConfigAppender) (soba.Appender, error) {
+		return appender, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level: "info",
+			Appenders: []string{
+				"default-plugin-log",
+			},
+		},
+		Appenders: map[string]soba.ConfigAppender{
+			"default-plugin-log": {
+				Type: "default-plugin",
+			},
+		},
+	}
+
+	err = soba.ValidateConfig(conf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	handler, err := soba.CreateWithConfig(conf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	handler.New("foo").Info("Hello world")
+	if appender.Size() != 1 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Size(), 1)
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+// QueueOptions defines the options of a custom appender type.
+type QueueOptions struct {
+	Topic      string   `yaml:"topic"`
+	Partitions int      `yaml:"partitions"`
+	Brokers    []string `yaml:"brokers"`
+}
 
-	dbAppender := NewTestAppender("db-log")
-	defer CloseAppender(t, dbAppender)
+// QueueAppender is a custom appender configured with options.
+type QueueAppender struct {
+	*TestAppender
+	options QueueOptions
+}
 
-	authAppender := NewTestAppender("auth-log")
-	defer CloseAppender(t, authAppender)
+// NewQueueAppender is the factory of QueueAppender.
+func NewQueueAppender(name string, conf soba.ConfigAppender) (soba.Appender, error) {
+	options := QueueOptions{}
+	err := conf.Options.Decode(&options)
+	if err != nil {
+		return nil, err
+	}
 
-	stdoutAppender := NewTestAppender("stdout")
-	defer CloseAppender(t, stdoutAppender)
+	return &QueueAppender{
+		TestAppender: NewTestAppender(name),
+		options:      options,
+	}, nil
+}
 
-	err := soba.RegisterAppenders(apiAppender, dbAppender, authAppender, stdoutAppender)
+// ValidateQueueAppender is the validation hook of QueueAppender.
+func ValidateQueueAppender(name string, conf soba.ConfigAppender) error {
+	options := QueueOptions{}
+	err := conf.Options.Decode(&options)
+	if err != nil {
+		return err
+	}
+	if options.Topic == "" {
+		return &soba.ConfigIssue{Path: "topic", Message: "topic is required"}
+	}
+	if options.Partitions < 0 {
+		return fmt.Errorf("partitions must be positive: %d", options.Partitions)
+	}
+	return nil
+}
+
+// registerQueueAppender registers QueueAppender for handlers created without a registry, once.
+var registerQueueAppender = sync.Once{}
+
+// Test options of a custom appender type declared in a configuration file.
+// nolint: gocyclo
+func TestPlugins_ConfigOptions(t *testing.T) {
+	registerQueueAppender.Do(func() {
+		err := soba.RegisterAppenderType("queue", NewQueueAppender)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		err = soba.RegisterAppenderValidator("queue", ValidateQueueAppender)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	})
+
+	conf, err := soba.ParseConfig("testdata/plugins.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	appender, err := soba.NewAppender("events", conf.Appenders["events"])
 	if err != nil {
 		t.Fatalf("Unexpected error: %+v", err)
 	}
+	defer CloseAppender(t, appender)
+
+	queue, ok := appender.(*QueueAppender)
+	if !ok {
+		t.Fatalf("Unexpected appender type: %T", appender)
+	}
 
-	invalidAppender := NewTestAppender("Notifier")
-	defer CloseAppender(t, invalidAppender)
+	options := queue.options
+	if options.Topic != "events" || options.Partitions != 3 || len(options.Brokers) != 2 ||
+		options.Brokers[1] != "10.0.0.2:9092" {
+		t.Fatalf("Unexpected options: %+v", options)
+	}
 
-	err = soba.RegisterAppenders(invalidAppender)
+	handler, err := soba.CreateWithConfig(conf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	// An invalid option is located in the configuration file.
+	_, err = soba.ParseConfig("testdata/options.yaml")
+	expected := "testdata/options.yaml:8: appenders.events.options.partitions: " +
+		"cannot decode options: cannot unmarshal !!str `two` into int"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Fatalf("Unexpected error: '%v' should contain '%s'", err, expected)
+	}
+}
+
+// Test validation of options for a custom appender type.
+func TestPlugins_ValidateOptions(t *testing.T) {
+	registry := soba.NewRegistry()
+
+	err := registry.RegisterAppenderValidator("queue", ValidateQueueAppender)
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	err = registry.RegisterAppenderType("queue", NewQueueAppender)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	err = registry.RegisterAppenderValidator("queue", nil)
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	err = registry.RegisterAppenderValidator("console", ValidateQueueAppender)
 	if err == nil {
 		t.Fatal("An error was expected")
 	}
+
+	err = registry.RegisterAppenderValidator("queue", ValidateQueueAppender)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	err = registry.RegisterAppenderValidator("queue", ValidateQueueAppender)
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	scenarios := []struct {
+		conf     soba.ConfigAppender
+		expected string
+	}{
+		{
+			conf: soba.ConfigAppender{
+				Type:    "queue",
+				Options: soba.NewConfigOptions(map[string]interface{}{"topic": "events", "partitions": 2}),
+			},
+			expected: "",
+		},
+		{
+			conf: soba.ConfigAppender{
+				Type:    "queue",
+				Options: soba.NewConfigOptions(map[string]interface{}{"topic": "events", "partition": 2}),
+			},
+			expected: fmt.Sprint(
+				`appenders.events.options.partition: cannot decode options: `,
+				`unknown key "partition" (did you mean "partitions"?)`,
+			),
+		},
+		{
+			conf: soba.ConfigAppender{
+				Type:    "queue",
+				Options: soba.NewConfigOptions(map[string]interface{}{"topic": "events", "partitions": "two"}),
+			},
+			expected: "appenders.events.options.partitions: cannot decode options: cannot unmarshal !!str `two` into int",
+		},
+		{
+			conf: soba.ConfigAppender{
+				Type:    "queue",
+				Options: soba.NewConfigOptions(map[string]interface{}{"partitions": 2}),
+			},
+			expected: "appenders.events.options.topic: topic is required",
+		},
+		{
+			conf: soba.ConfigAppender{
+				Type:    "queue",
+				Options: soba.NewConfigOptions(map[string]interface{}{"topic": "events", "partitions": -1}),
+			},
+			expected: "appenders.events: partitions must be positive: -1",
+		},
+		{
+			conf: soba.ConfigAppender{
+				Type:    "console",
+				Options: soba.NewConfigOptions(map[string]interface{}{"topic": "events"}),
+			},
+			expected: `appenders.events.options: options are not supported by appender type "console"`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		_, err := soba.CreateWithRegistry(&soba.Config{
+			Root: soba.ConfigLogger{
+				Level: "info",
+				Appenders: []string{
+					"events",
+				},
+			},
+			Appenders: map[string]soba.ConfigAppender{
+				"events": scenario.conf,
+			},
+		}, registry)
+
+		if scenario.expected == "" && err != nil {
+			t.Fatalf("Unexpected error for %+v: %+v", scenario.conf, err)
+		}
+		if scenario.expected != "" && (err == nil || !strings.Contains(err.Error(), scenario.expected)) {
+			t.Fatalf("Unexpected error for %+v: '%v' should contain '%s'", scenario.conf, err, scenario.expected)
+		}
+	}
 }
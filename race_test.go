@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package soba_test
+
+// raceEnabled defines if the race detector is enabled, since it adds memory allocations.
+const raceEnabled = true
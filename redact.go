@@ -0,0 +1,686 @@
+package soba
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// MaskRedactAction replaces the value of a field with a mask.
+	MaskRedactAction = "mask"
+	// DropRedactAction removes a field.
+	DropRedactAction = "drop"
+)
+
+// DefaultRedactMask is the default mask used to replace a sensitive value.
+const DefaultRedactMask = "[REDACTED]"
+
+// Redactable is implemented by values that hold sensitive data, such as credentials.
+// When a Redactable value is encoded by a logger, its Redact method is used instead of its content.
+type Redactable interface {
+	Redact() string
+}
+
+// A Redactor masks or drops sensitive data from log entries.
+//
+// Fields are matched by their key with a glob pattern: a pattern without a "." matches a key at any depth,
+// such as "password", whereas a pattern with a "." matches the full path of a key, such as "*.token".
+// String values are matched with a regular expression, such as a card number or a bearer token.
+// Since it applies inside encoding, the output of a nested ObjectMarshaler or ArrayMarshaler is also covered.
+type Redactor struct {
+	mask   string
+	keys   []redactKeyRule
+	values []redactValueRule
+}
+
+// redactKeyRule is a rule that masks or drops a field identified by its key.
+type redactKeyRule struct {
+	pattern string
+	path    bool
+	drop    bool
+}
+
+// redactValueRule is a rule that masks a part of a string value.
+type redactValueRule struct {
+	pattern *regexp.Regexp
+	mask    string
+}
+
+// NewRedactor creates a new Redactor from given configuration.
+func NewRedactor(conf ConfigRedact) (*Redactor, error) {
+	validator := newConfigValidator(nil)
+	validateRedactConfig(conf, validator)
+	err := validator.err()
+	if err != nil {
+		return nil, err
+	}
+
+	redactor := &Redactor{
+		mask:   conf.Mask,
+		keys:   make([]redactKeyRule, 0, len(conf.Keys)),
+		values: make([]redactValueRule, 0, len(conf.Values)),
+	}
+
+	if redactor.mask == "" {
+		redactor.mask = DefaultRedactMask
+	}
+
+	for _, rule := range conf.Keys {
+		redactor.keys = append(redactor.keys, redactKeyRule{
+			pattern: rule.Pattern,
+			path:    strings.Contains(rule.Pattern, "."),
+			drop:    rule.Action == DropRedactAction,
+		})
+	}
+
+	for _, rule := range conf.Values {
+		mask := rule.Mask
+		if mask == "" {
+			mask = redactor.mask
+		}
+
+		redactor.values = append(redactor.values, redactValueRule{
+			// Regular expression is verified by validateRedactConfig function.
+			pattern: regexp.MustCompile(rule.Pattern),
+			mask:    mask,
+		})
+	}
+
+	return redactor, nil
+}
+
+// IsEmpty returns if the redactor has no rules.
+func (redactor *Redactor) IsEmpty() bool {
+	return redactor == nil || (len(redactor.keys) == 0 && len(redactor.values) == 0)
+}
+
+// Encoder returns an Encoder that applies the redactor rules before writing on given encoder.
+// A Redactable value is always redacted, even if the redactor is nil or has no rules.
+func (redactor *Redactor) Encoder(encoder Encoder) Encoder {
+	return &redactEncoder{
+		redactor: redactor,
+		parent:   encoder,
+		object:   encoder,
+		array:    encoder,
+	}
+}
+
+// prefixedEncoder returns an Encoder that applies the redactor rules before writing on given encoder, for fields
+// whose keys have been prefixed with given prefix: the prefix is ignored when the rules are matched.
+func (redactor *Redactor) prefixedEncoder(encoder Encoder, prefix string) Encoder {
+	return &redactEncoder{
+		redactor: redactor,
+		strip:    prefix,
+		parent:   encoder,
+		object:   encoder,
+		array:    encoder,
+	}
+}
+
+// reusedEncoder is like prefixedEncoder, but it initializes and returns given encoder rather than allocating a new
+// one.
+func (redactor *Redactor) reusedEncoder(reused *redactEncoder, encoder Encoder, prefix string) Encoder {
+	*reused = redactEncoder{
+		redactor: redactor,
+		strip:    prefix,
+		parent:   encoder,
+		object:   encoder,
+		array:    encoder,
+	}
+
+	return reused
+}
+
+// fieldEncoder returns the encoder used to write given field: either the redacted encoder, or the raw encoder if the
+// redactor has no rules and the field value cannot be Redactable.
+func (redactor *Redactor) fieldEncoder(redacted Encoder, raw Encoder, field Field) Encoder {
+	if redactor.IsEmpty() && !field.isRedactable() {
+		return raw
+	}
+	return redacted
+}
+
+// String applies the redactor value rules on given string.
+func (redactor *Redactor) String(value string) string {
+	if redactor == nil {
+		return value
+	}
+
+	for i := range redactor.values {
+		value = redactor.values[i].pattern.ReplaceAllLiteralString(value, redactor.values[i].mask)
+	}
+
+	return value
+}
+
+// match returns if a field identified by given key and path should be redacted, and if it should be dropped.
+func (redactor *Redactor) match(key string, prefix string) (bool, bool) {
+	if redactor == nil {
+		return false, false
+	}
+
+	for i := range redactor.keys {
+		rule := redactor.keys[i]
+
+		name := key
+		if rule.path {
+			name = prefix + key
+		}
+
+		ok, _ := path.Match(rule.pattern, name)
+		if ok {
+			return true, rule.drop
+		}
+	}
+
+	return false, false
+}
+
+// redactEncoder is an Encoder that applies redactor rules before writing on its parent encoder.
+type redactEncoder struct {
+	redactor *Redactor
+	prefix   string
+	strip    string
+	parent   Encoder
+	object   ObjectEncoder
+	array    ArrayEncoder
+}
+
+// Bytes returns the encoder content buffer.
+func (encoder *redactEncoder) Bytes() []byte {
+	return encoder.parent.Bytes()
+}
+
+// Close recycles underlying resources of encoder.
+func (encoder *redactEncoder) Close() {
+	encoder.parent.Close()
+}
+
+// Encode start the initialization of a new instance/object.
+func (encoder *redactEncoder) Encode(handler func(encoder Encoder)) []byte {
+	return encoder.parent.Encode(func(parent Encoder) {
+		handler(encoder.redactor.Encoder(parent))
+	})
+}
+
+// redact applies the key rules on given key: it returns true if the field was dropped or masked.
+func (encoder *redactEncoder) redact(key string) bool {
+	redacted, drop := encoder.redactor.match(strings.TrimPrefix(key, encoder.strip), encoder.prefix)
+	if !redacted {
+		return false
+	}
+	if !drop {
+		encoder.object.AddString(key, encoder.redactor.mask)
+	}
+	return true
+}
+
+// nested returns an encoder for the object or the array identified by given key.
+func (encoder *redactEncoder) nested(key string) *redactEncoder {
+	return &redactEncoder{
+		redactor: encoder.redactor,
+		prefix:   encoder.prefix + strings.TrimPrefix(key, encoder.strip) + ".",
+		parent:   encoder.parent,
+		object:   encoder.object,
+		array:    encoder.array,
+	}
+}
+
+// redactObject is an ObjectMarshaler that applies redactor rules on another ObjectMarshaler.
+type redactObject struct {
+	encoder *redactEncoder
+	value   ObjectMarshaler
+}
+
+// Encode registers object properties in the logging context.
+func (object redactObject) Encode(encoder ObjectEncoder) {
+	child := *object.encoder
+	child.object = encoder
+	object.value.Encode(&child)
+}
+
+// redactArray is an ArrayMarshaler that applies redactor rules on another ArrayMarshaler.
+type redactArray struct {
+	encoder *redactEncoder
+	value   ArrayMarshaler
+}
+
+// Encode registers array elements in the logging context.
+func (array redactArray) Encode(encoder ArrayEncoder) {
+	child := *array.encoder
+	child.array = encoder
+	array.value.Encode(&child)
+}
+
+// AddArray adds the field key with given ArrayMarshaler to the encoder.
+func (encoder *redactEncoder) AddArray(key string, value ArrayMarshaler) {
+	if encoder.redact(key) {
+		return
+	}
+	redactable, ok := value.(Redactable)
+	if ok {
+		encoder.object.AddString(key, redactable.Redact())
+		return
+	}
+	encoder.object.AddArray(key, redactArray{encoder: encoder.nested(key), value: value})
+}
+
+// AddObject adds the field key with given ObjectMarshaler to the encoder.
+func (encoder *redactEncoder) AddObject(key string, value ObjectMarshaler) {
+	if encoder.redact(key) {
+		return
+	}
+	redactable, ok := value.(Redactable)
+	if ok {
+		encoder.object.AddString(key, redactable.Redact())
+		return
+	}
+	encoder.object.AddObject(key, redactObject{encoder: encoder.nested(key), value: value})
+}
+
+// AddObjects adds the field key with given list of ObjectMarshaler to the encoder.
+func (encoder *redactEncoder) AddObjects(key string, values []ObjectMarshaler) {
+	if encoder.redact(key) {
+		return
+	}
+	encoder.object.AddArray(key, redactArray{encoder: encoder.nested(key), value: redactObjects(values)})
+}
+
+// redactObjects is an ArrayMarshaler for a list of ObjectMarshaler.
+type redactObjects []ObjectMarshaler
+
+// Encode registers array elements in the logging context.
+func (values redactObjects) Encode(encoder ArrayEncoder) {
+	for i := range values {
+		encoder.AppendObject(values[i])
+	}
+}
+
+// AddInt adds the field key with given integer to the encoder.
+func (encoder *redactEncoder) AddInt(key string, value int) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt(key, value)
+	}
+}
+
+// AddInts adds the field key with given list of integer to the encoder.
+func (encoder *redactEncoder) AddInts(key string, values []int) {
+	if !encoder.redact(key) {
+		encoder.object.AddInts(key, values)
+	}
+}
+
+// AddInt8 adds the field key with given integer to the encoder.
+func (encoder *redactEncoder) AddInt8(key string, value int8) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt8(key, value)
+	}
+}
+
+// AddInt8s adds the field key with given list of integer to the encoder.
+func (encoder *redactEncoder) AddInt8s(key string, values []int8) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt8s(key, values)
+	}
+}
+
+// AddInt16 adds the field key with given integer to the encoder.
+func (encoder *redactEncoder) AddInt16(key string, value int16) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt16(key, value)
+	}
+}
+
+// AddInt16s adds the field key with given list of integer to the encoder.
+func (encoder *redactEncoder) AddInt16s(key string, values []int16) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt16s(key, values)
+	}
+}
+
+// AddInt32 adds the field key with given integer to the encoder.
+func (encoder *redactEncoder) AddInt32(key string, value int32) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt32(key, value)
+	}
+}
+
+// AddInt32s adds the field key with given list of integer to the encoder.
+func (encoder *redactEncoder) AddInt32s(key string, values []int32) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt32s(key, values)
+	}
+}
+
+// AddInt64 adds the field key with given integer to the encoder.
+func (encoder *redactEncoder) AddInt64(key string, value int64) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt64(key, value)
+	}
+}
+
+// AddInt64s adds the field key with given list of integer to the encoder.
+func (encoder *redactEncoder) AddInt64s(key string, values []int64) {
+	if !encoder.redact(key) {
+		encoder.object.AddInt64s(key, values)
+	}
+}
+
+// AddUint adds the field key with given unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint(key string, value uint) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint(key, value)
+	}
+}
+
+// AddUints adds the field key with given list of unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUints(key string, values []uint) {
+	if !encoder.redact(key) {
+		encoder.object.AddUints(key, values)
+	}
+}
+
+// AddUint8 adds the field key with given unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint8(key string, value uint8) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint8(key, value)
+	}
+}
+
+// AddUint8s adds the field key with given list of unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint8s(key string, values []uint8) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint8s(key, values)
+	}
+}
+
+// AddUint16 adds the field key with given unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint16(key string, value uint16) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint16(key, value)
+	}
+}
+
+// AddUint16s adds the field key with given list of unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint16s(key string, values []uint16) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint16s(key, values)
+	}
+}
+
+// AddUint32 adds the field key with given unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint32(key string, value uint32) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint32(key, value)
+	}
+}
+
+// AddUint32s adds the field key with given list of unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint32s(key string, values []uint32) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint32s(key, values)
+	}
+}
+
+// AddUint64 adds the field key with given unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint64(key string, value uint64) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint64(key, value)
+	}
+}
+
+// AddUint64s adds the field key with given list of unsigned integer to the encoder.
+func (encoder *redactEncoder) AddUint64s(key string, values []uint64) {
+	if !encoder.redact(key) {
+		encoder.object.AddUint64s(key, values)
+	}
+}
+
+// AddFloat32 adds the field key with given number to the encoder.
+func (encoder *redactEncoder) AddFloat32(key string, value float32) {
+	if !encoder.redact(key) {
+		encoder.object.AddFloat32(key, value)
+	}
+}
+
+// AddFloat32s adds the field key with given list of number to the encoder.
+func (encoder *redactEncoder) AddFloat32s(key string, values []float32) {
+	if !encoder.redact(key) {
+		encoder.object.AddFloat32s(key, values)
+	}
+}
+
+// AddFloat64 adds the field key with given number to the encoder.
+func (encoder *redactEncoder) AddFloat64(key string, value float64) {
+	if !encoder.redact(key) {
+		encoder.object.AddFloat64(key, value)
+	}
+}
+
+// AddFloat64s adds the field key with given list of number to the encoder.
+func (encoder *redactEncoder) AddFloat64s(key string, values []float64) {
+	if !encoder.redact(key) {
+		encoder.object.AddFloat64s(key, values)
+	}
+}
+
+// AddString adds the field key with given string to the encoder.
+func (encoder *redactEncoder) AddString(key string, value string) {
+	if !encoder.redact(key) {
+		encoder.object.AddString(key, encoder.redactor.String(value))
+	}
+}
+
+// AddStrings adds the field key with given list of string to the encoder.
+func (encoder *redactEncoder) AddStrings(key string, values []string) {
+	if encoder.redact(key) {
+		return
+	}
+	list := make([]string, len(values))
+	for i := range values {
+		list[i] = encoder.redactor.String(values[i])
+	}
+	encoder.object.AddStrings(key, list)
+}
+
+// AddStringer adds the field key with given Stringer to the encoder.
+func (encoder *redactEncoder) AddStringer(key string, value fmt.Stringer) {
+	if encoder.redact(key) {
+		return
+	}
+	redactable, ok := value.(Redactable)
+	if ok {
+		encoder.object.AddString(key, redactable.Redact())
+		return
+	}
+	encoder.object.AddString(key, encoder.redactor.String(value.String()))
+}
+
+// AddStringers adds the field key with given list of Stringer to the encoder.
+func (encoder *redactEncoder) AddStringers(key string, values []fmt.Stringer) {
+	if encoder.redact(key) {
+		return
+	}
+	list := make([]string, len(values))
+	for i := range values {
+		redactable, ok := values[i].(Redactable)
+		if ok {
+			list[i] = redactable.Redact()
+		} else {
+			list[i] = encoder.redactor.String(values[i].String())
+		}
+	}
+	encoder.object.AddStrings(key, list)
+}
+
+// AddTime adds the field key with given Time to the encoder.
+func (encoder *redactEncoder) AddTime(key string, value time.Time) {
+	if !encoder.redact(key) {
+		encoder.object.AddTime(key, value)
+	}
+}
+
+// AddTimes adds the field key with given list of Time to the encoder.
+func (encoder *redactEncoder) AddTimes(key string, values []time.Time) {
+	if !encoder.redact(key) {
+		encoder.object.AddTimes(key, values)
+	}
+}
+
+// AddDuration adds the field key with given Duration to the encoder.
+func (encoder *redactEncoder) AddDuration(key string, value time.Duration) {
+	if !encoder.redact(key) {
+		encoder.object.AddDuration(key, value)
+	}
+}
+
+// AddDurations adds the field key with given list of Duration to the encoder.
+func (encoder *redactEncoder) AddDurations(key string, values []time.Duration) {
+	if !encoder.redact(key) {
+		encoder.object.AddDurations(key, values)
+	}
+}
+
+// AddBool adds the field key with given boolean to the encoder.
+func (encoder *redactEncoder) AddBool(key string, value bool) {
+	if !encoder.redact(key) {
+		encoder.object.AddBool(key, value)
+	}
+}
+
+// AddBools adds the field key with given list of boolean to the encoder.
+func (encoder *redactEncoder) AddBools(key string, values []bool) {
+	if !encoder.redact(key) {
+		encoder.object.AddBools(key, values)
+	}
+}
+
+// AddBinary adds the field key with given buffer or bytes to the encoder.
+func (encoder *redactEncoder) AddBinary(key string, value []byte) {
+	if !encoder.redact(key) {
+		encoder.object.AddBinary(key, value)
+	}
+}
+
+// AddNull adds the field key as a null value to the encoder.
+func (encoder *redactEncoder) AddNull(key string) {
+	if !encoder.redact(key) {
+		encoder.object.AddNull(key)
+	}
+}
+
+// AppendArray appends given ArrayMarshaler to the encoder.
+func (encoder *redactEncoder) AppendArray(value ArrayMarshaler) {
+	redactable, ok := value.(Redactable)
+	if ok {
+		encoder.array.AppendString(redactable.Redact())
+		return
+	}
+	encoder.array.AppendArray(redactArray{encoder: encoder, value: value})
+}
+
+// AppendObject appends given ObjectMarshaler to the encoder.
+func (encoder *redactEncoder) AppendObject(value ObjectMarshaler) {
+	redactable, ok := value.(Redactable)
+	if ok {
+		encoder.array.AppendString(redactable.Redact())
+		return
+	}
+	encoder.array.AppendObject(redactObject{encoder: encoder, value: value})
+}
+
+// AppendInt appends given integer to the encoder.
+func (encoder *redactEncoder) AppendInt(value int) {
+	encoder.array.AppendInt(value)
+}
+
+// AppendInt8 appends given integer to the encoder.
+func (encoder *redactEncoder) AppendInt8(value int8) {
+	encoder.array.AppendInt8(value)
+}
+
+// AppendInt16 appends given integer to the encoder.
+func (encoder *redactEncoder) AppendInt16(value int16) {
+	encoder.array.AppendInt16(value)
+}
+
+// AppendInt32 appends given integer to the encoder.
+func (encoder *redactEncoder) AppendInt32(value int32) {
+	encoder.array.AppendInt32(value)
+}
+
+// AppendInt64 appends given integer to the encoder.
+func (encoder *redactEncoder) AppendInt64(value int64) {
+	encoder.array.AppendInt64(value)
+}
+
+// AppendUint appends given unsigned integer to the encoder.
+func (encoder *redactEncoder) AppendUint(value uint) {
+	encoder.array.AppendUint(value)
+}
+
+// AppendUint8 appends given unsigned integer to the encoder.
+func (encoder *redactEncoder) AppendUint8(value uint8) {
+	encoder.array.AppendUint8(value)
+}
+
+// AppendUint16 appends given unsigned integer to the encoder.
+func (encoder *redactEncoder) AppendUint16(value uint16) {
+	encoder.array.AppendUint16(value)
+}
+
+// AppendUint32 appends given unsigned integer to the encoder.
+func (encoder *redactEncoder) AppendUint32(value uint32) {
+	encoder.array.AppendUint32(value)
+}
+
+// AppendUint64 appends given unsigned integer to the encoder.
+func (encoder *redactEncoder) AppendUint64(value uint64) {
+	encoder.array.AppendUint64(value)
+}
+
+// AppendFloat32 appends given number to the encoder.
+func (encoder *redactEncoder) AppendFloat32(value float32) {
+	encoder.array.AppendFloat32(value)
+}
+
+// AppendFloat64 appends given number to the encoder.
+func (encoder *redactEncoder) AppendFloat64(value float64) {
+	encoder.array.AppendFloat64(value)
+}
+
+// AppendString appends given string to the encoder.
+func (encoder *redactEncoder) AppendString(value string) {
+	encoder.array.AppendString(encoder.redactor.String(value))
+}
+
+// AppendTime appends given Time to the encoder.
+func (encoder *redactEncoder) AppendTime(value time.Time) {
+	encoder.array.AppendTime(value)
+}
+
+// AppendDuration appends given Duration to the encoder.
+func (encoder *redactEncoder) AppendDuration(value time.Duration) {
+	encoder.array.AppendDuration(value)
+}
+
+// AppendBool appends given boolean to the encoder.
+func (encoder *redactEncoder) AppendBool(value bool) {
+	encoder.array.AppendBool(value)
+}
+
+// AppendBinary appends given buffer or bytes to the encoder.
+func (encoder *redactEncoder) AppendBinary(value []byte) {
+	encoder.array.AppendBinary(value)
+}
+
+// AppendNull appends a null value to the encoder.
+func (encoder *redactEncoder) AppendNull() {
+	encoder.array.AppendNull()
+}
+
+// Ensure redactEncoder implements Encoder interface at compile time.
+var _ Encoder = &redactEncoder{}
@@ -0,0 +1,396 @@
+package soba
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelFormat defines how the level of an entry is written.
+type LevelFormat uint8
+
+const (
+	// LowerLevelFormat writes the level name in lowercase: "info".
+	LowerLevelFormat = LevelFormat(iota)
+	// UpperLevelFormat writes the level name in uppercase: "INFO".
+	UpperLevelFormat
+	// SyslogLevelFormat writes the level as a syslog severity number: 6 for info.
+	SyslogLevelFormat
+)
+
+const (
+	strLowerLevelFormat  = "lower"
+	strUpperLevelFormat  = "upper"
+	strSyslogLevelFormat = "syslog"
+)
+
+// Convert the LevelFormat to a string.
+func (format LevelFormat) String() string {
+	switch format {
+	case UpperLevelFormat:
+		return strUpperLevelFormat
+	case SyslogLevelFormat:
+		return strSyslogLevelFormat
+	default:
+		return strLowerLevelFormat
+	}
+}
+
+// ParseLevelFormat takes a string level format and returns the level format constant.
+// An empty string is considered as LowerLevelFormat.
+func ParseLevelFormat(format string) (LevelFormat, bool) {
+	switch format {
+	case strLowerLevelFormat, "":
+		return LowerLevelFormat, true
+	case strUpperLevelFormat:
+		return UpperLevelFormat, true
+	case strSyslogLevelFormat:
+		return SyslogLevelFormat, true
+	default:
+		return LowerLevelFormat, false
+	}
+}
+
+// TimeFormat defines how the timestamp of an entry is written.
+type TimeFormat uint8
+
+const (
+	// RFC3339TimeFormat writes the timestamp as a RFC3339 string: "2019-04-20T09:53:13Z".
+	RFC3339TimeFormat = TimeFormat(iota)
+	// UnixTimeFormat writes the timestamp as a number of seconds since the Unix epoch: 1555754093.
+	UnixTimeFormat
+)
+
+const (
+	strRFC3339TimeFormat = "rfc3339"
+	strUnixTimeFormat    = "unix"
+)
+
+// Convert the TimeFormat to a string.
+func (format TimeFormat) String() string {
+	switch format {
+	case UnixTimeFormat:
+		return strUnixTimeFormat
+	default:
+		return strRFC3339TimeFormat
+	}
+}
+
+// ParseTimeFormat takes a string time format and returns the time format constant.
+// An empty string is considered as RFC3339TimeFormat.
+func ParseTimeFormat(format string) (TimeFormat, bool) {
+	switch format {
+	case strRFC3339TimeFormat, "":
+		return RFC3339TimeFormat, true
+	case strUnixTimeFormat:
+		return UnixTimeFormat, true
+	default:
+		return RFC3339TimeFormat, false
+	}
+}
+
+// Schema defines the layout of an entry once encoded: the key names of its built-in fields, and how they are
+// written.
+type Schema struct {
+	// Name is the name of the schema.
+	Name string
+	// LoggerKey is the key used for the entry name. If it's empty, the entry name is omitted.
+	LoggerKey string
+	// TimeKey is the key used for the entry timestamp.
+	TimeKey string
+	// LevelKey is the key used for the entry level.
+	LevelKey string
+	// MessageKey is the key used for the entry message.
+	MessageKey string
+	// LevelFormat defines how the entry level is written.
+	LevelFormat LevelFormat
+	// TimeFormat defines how the entry timestamp is written.
+	TimeFormat TimeFormat
+	// FieldPrefix is a prefix added to the key of every field of the entry.
+	FieldPrefix string
+	// Fields is a list of static fields written after the built-in fields.
+	Fields []Field
+}
+
+const (
+	// DefaultSchemaName defines the name of the default schema.
+	DefaultSchemaName = "soba"
+	// ECSSchemaName defines the name of the Elastic Common Schema.
+	ECSSchemaName = "ecs"
+	// GCPSchemaName defines the name of the Google Cloud Logging schema.
+	GCPSchemaName = "gcp"
+	// GELFSchemaName defines the name of the Graylog Extended Log Format schema.
+	GELFSchemaName = "gelf"
+)
+
+var (
+	// DefaultSchema is the schema used by default.
+	DefaultSchema = &Schema{
+		Name:       DefaultSchemaName,
+		LoggerKey:  LoggerKey,
+		TimeKey:    TimeKey,
+		LevelKey:   LevelKey,
+		MessageKey: MessageKey,
+	}
+	// ECSSchema is the schema for Elastic Common Schema.
+	ECSSchema = &Schema{
+		Name:       ECSSchemaName,
+		LoggerKey:  "log.logger",
+		TimeKey:    "@timestamp",
+		LevelKey:   "log.level",
+		MessageKey: "message",
+		Fields: []Field{
+			String("ecs.version", "1.6.0"),
+		},
+	}
+	// GCPSchema is the schema for Google Cloud Logging.
+	GCPSchema = &Schema{
+		Name:        GCPSchemaName,
+		LoggerKey:   "logger",
+		TimeKey:     "timestamp",
+		LevelKey:    "severity",
+		MessageKey:  "message",
+		LevelFormat: UpperLevelFormat,
+	}
+	// GELFSchema is the schema for Graylog Extended Log Format.
+	GELFSchema = &Schema{
+		Name:        GELFSchemaName,
+		LoggerKey:   "_logger",
+		TimeKey:     "timestamp",
+		LevelKey:    "level",
+		MessageKey:  "short_message",
+		LevelFormat: SyslogLevelFormat,
+		TimeFormat:  UnixTimeFormat,
+		FieldPrefix: "_",
+		Fields: []Field{
+			String("version", "1.1"),
+			String("host", getHostname()),
+		},
+	}
+)
+
+// GetSchema returns the preset schema with given name, such as "ecs", "gcp" or "gelf".
+// An empty name is considered as DefaultSchemaName.
+func GetSchema(name string) (*Schema, bool) {
+	switch name {
+	case DefaultSchemaName, "":
+		return DefaultSchema, true
+	case ECSSchemaName:
+		return ECSSchema, true
+	case GCPSchemaName:
+		return GCPSchema, true
+	case GELFSchemaName:
+		return GELFSchema, true
+	default:
+		return nil, false
+	}
+}
+
+// IsSchemaNameValid verify that a preset schema name is allowed.
+func IsSchemaNameValid(name string) bool {
+	_, ok := GetSchema(name)
+	return ok
+}
+
+// schemas is a cache of schemas created from configuration, so appenders with the same schema configuration
+// share the same encoded entry.
+var schemas = sync.Map{}
+
+// NewSchema creates a schema from given configuration.
+func NewSchema(conf ConfigSchema) (*Schema, error) {
+	validator := newConfigValidator(nil)
+	validateSchemaConfig(conf, "schema", validator)
+	err := validator.err()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := schemas.Load(conf)
+	if ok {
+		return value.(*Schema), nil
+	}
+
+	preset, _ := GetSchema(conf.Preset)
+	if conf == (ConfigSchema{Preset: conf.Preset}) {
+		return preset, nil
+	}
+
+	schema := *preset
+	if conf.LoggerKey != "" {
+		schema.LoggerKey = conf.LoggerKey
+	}
+	if conf.TimeKey != "" {
+		schema.TimeKey = conf.TimeKey
+	}
+	if conf.LevelKey != "" {
+		schema.LevelKey = conf.LevelKey
+	}
+	if conf.MessageKey != "" {
+		schema.MessageKey = conf.MessageKey
+	}
+	if conf.OmitLogger {
+		schema.LoggerKey = ""
+	}
+	if conf.LevelFormat != "" {
+		schema.LevelFormat, _ = ParseLevelFormat(conf.LevelFormat)
+	}
+	if conf.TimeFormat != "" {
+		schema.TimeFormat, _ = ParseTimeFormat(conf.TimeFormat)
+	}
+
+	value, _ = schemas.LoadOrStore(conf, &schema)
+
+	return value.(*Schema), nil
+}
+
+// FormatLevel returns the value written for given level, as a string.
+func (schema *Schema) FormatLevel(level Level) string {
+	switch schema.LevelFormat {
+	case UpperLevelFormat:
+		return getUpperLevelName(level)
+	case SyslogLevelFormat:
+		return getSyslogSeverityName(level)
+	default:
+		return level.String()
+	}
+}
+
+// writeTime writes the entry timestamp on given encoder.
+func (schema *Schema) writeTime(encoder Encoder, unix int64) {
+	switch schema.TimeFormat {
+	case UnixTimeFormat:
+		encoder.AddInt64(schema.TimeKey, unix)
+	default:
+		encoder.AddTime(schema.TimeKey, time.Unix(unix, 0).UTC())
+	}
+}
+
+// writeLevel writes the entry level on given encoder.
+func (schema *Schema) writeLevel(encoder Encoder, level Level) {
+	switch schema.LevelFormat {
+	case UpperLevelFormat:
+		encoder.AddString(schema.LevelKey, getUpperLevelName(level))
+	case SyslogLevelFormat:
+		encoder.AddInt(schema.LevelKey, getSyslogSeverity(level))
+	default:
+		encoder.AddStringer(schema.LevelKey, level)
+	}
+}
+
+// writeField writes given field on given encoder, with the field prefix of the schema.
+// If protect is enabled, a field key that overwrites a reserved key of the schema is prefixed with
+// ProtectedKeyPrefix.
+func (schema *Schema) writeField(encoder Encoder, field Field, protect bool) {
+	key := field.name
+	if protect && schema.isReservedKey(key) {
+		key = ProtectedKeyPrefix + key
+	}
+	if schema.FieldPrefix != "" {
+		key = schema.FieldPrefix + key
+	}
+	field.write(encoder, key)
+}
+
+// isReservedKey returns if given field key, once prefixed with the field prefix, is used by the schema itself:
+// either by a built-in field, such as the message, or by a static field.
+func (schema *Schema) isReservedKey(key string) bool {
+	if schema.hasKey(schema.LoggerKey, key) || schema.hasKey(schema.TimeKey, key) ||
+		schema.hasKey(schema.LevelKey, key) || schema.hasKey(schema.MessageKey, key) {
+		return true
+	}
+
+	for i := range schema.Fields {
+		if schema.hasKey(schema.Fields[i].name, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasKey returns if given field key, once prefixed with the field prefix, is equal to given reserved key.
+func (schema *Schema) hasKey(reserved string, key string) bool {
+	return reserved != "" && len(reserved) == len(schema.FieldPrefix)+len(key) &&
+		strings.HasPrefix(reserved, schema.FieldPrefix) && strings.HasSuffix(reserved, key)
+}
+
+// getUpperLevelName returns the level name in uppercase.
+func getUpperLevelName(level Level) string {
+	switch level {
+	case NoLevel:
+		return "NEVER"
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// getSyslogSeverity returns the syslog severity number of given level.
+func getSyslogSeverity(level Level) int {
+	switch level {
+	case ErrorLevel:
+		return 3
+	case WarnLevel:
+		return 4
+	case InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// getSyslogSeverityName returns the syslog severity number of given level, as a string.
+func getSyslogSeverityName(level Level) string {
+	switch level {
+	case ErrorLevel:
+		return "3"
+	case WarnLevel:
+		return "4"
+	case InfoLevel:
+		return "6"
+	default:
+		return "7"
+	}
+}
+
+// getHostname returns the host name, or "localhost" if it's unavailable.
+func getHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "localhost"
+	}
+	return hostname
+}
+
+func validateSchemaConfig(conf ConfigSchema, path string, validator *configValidator) {
+
+	if !IsSchemaNameValid(conf.Preset) {
+		validator.errorf(joinPath(path, "preset"), `unknown schema preset "%s"%s`, conf.Preset,
+			suggest(conf.Preset, schemaNames))
+	}
+
+	_, ok := ParseLevelFormat(conf.LevelFormat)
+	if !ok {
+		validator.errorf(joinPath(path, "level_format"), `unknown level format "%s"%s`, conf.LevelFormat,
+			suggest(conf.LevelFormat, []string{strLowerLevelFormat, strUpperLevelFormat, strSyslogLevelFormat}))
+	}
+
+	_, ok = ParseTimeFormat(conf.TimeFormat)
+	if !ok {
+		validator.errorf(joinPath(path, "time_format"), `unknown time format "%s"%s`, conf.TimeFormat,
+			suggest(conf.TimeFormat, []string{strRFC3339TimeFormat, strUnixTimeFormat}))
+	}
+}
+
+// schemaNames is the list of schema names suggested for an invalid schema preset.
+var schemaNames = []string{
+	DefaultSchemaName, ECSSchemaName, GCPSchemaName, GELFSchemaName,
+}
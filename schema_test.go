@@ -0,0 +1,436 @@
+package soba_test
+
+import (
+	stdjson "encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+// SchemaAppender is an appender that keeps the entries encoded with a schema.
+type SchemaAppender struct {
+	name    string
+	schema  *soba.Schema
+	entries []string
+}
+
+func (appender *SchemaAppender) Name() string {
+	return appender.name
+}
+
+func (SchemaAppender) Close() error {
+	return nil
+}
+
+func (appender *SchemaAppender) Write(entry *soba.Entry) {
+	buffer := entry.EncodeWithSchema(soba.JSONEncoderType, appender.schema)
+	appender.entries = append(appender.entries, string(buffer))
+}
+
+// DecodeSchemaEntry decodes given entry line, and removes its timestamp after checking its type.
+func DecodeSchemaEntry(t *testing.T, line string, key string, unix bool) map[string]interface{} {
+	values := map[string]interface{}{}
+
+	err := stdjson.Unmarshal([]byte(line), &values)
+	if err != nil {
+		t.Fatalf("Unexpected error for '%s': %+v", line, err)
+	}
+
+	timestamp, ok := values[key]
+	if !ok {
+		t.Fatalf("Timestamp '%s' was expected for '%s'", key, line)
+	}
+
+	_, isNumber := timestamp.(float64)
+	_, isString := timestamp.(string)
+	if (unix && !isNumber) || (!unix && !isString) {
+		t.Fatalf("Unexpected timestamp '%s' for '%s'", key, line)
+	}
+
+	delete(values, key)
+
+	return values
+}
+
+// Test entry layout with every preset schema.
+func TestSchema_Presets(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	scenarios := []struct {
+		schema   *soba.Schema
+		unix     bool
+		expected map[string]interface{}
+	}{
+		{
+			schema: soba.DefaultSchema,
+			expected: map[string]interface{}{
+				"logger":  "foobar",
+				"level":   "warning",
+				"message": "Cache is full",
+				"size":    float64(42),
+			},
+		},
+		{
+			schema: soba.ECSSchema,
+			expected: map[string]interface{}{
+				"log.logger":  "foobar",
+				"log.level":   "warning",
+				"message":     "Cache is full",
+				"ecs.version": "1.6.0",
+				"size":        float64(42),
+			},
+		},
+		{
+			schema: soba.GCPSchema,
+			expected: map[string]interface{}{
+				"logger":   "foobar",
+				"severity": "WARNING",
+				"message":  "Cache is full",
+				"size":     float64(42),
+			},
+		},
+		{
+			schema: soba.GELFSchema,
+			unix:   true,
+			expected: map[string]interface{}{
+				"_logger":       "foobar",
+				"level":         float64(4),
+				"short_message": "Cache is full",
+				"version":       "1.1",
+				"host":          hostname,
+				"_size":         float64(42),
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		entry := soba.NewEntry("foobar", soba.WarnLevel, "Cache is full", []soba.Field{
+			soba.Int("size", 42),
+		})
+
+		line := string(entry.EncodeWithSchema(soba.JSONEncoderType, scenario.schema))
+		entry.Flush()
+
+		values := DecodeSchemaEntry(t, line, scenario.schema.TimeKey, scenario.unix)
+		if !reflect.DeepEqual(values, scenario.expected) {
+			t.Fatalf("Unexpected entry for schema %s: %+v should be %+v", scenario.schema.Name,
+				values, scenario.expected)
+		}
+
+		schema, ok := soba.GetSchema(scenario.schema.Name)
+		if !ok || schema != scenario.schema {
+			t.Fatalf("Unexpected preset for schema %s", scenario.schema.Name)
+		}
+	}
+}
+
+// DecodeSchemaKeys returns the keys of given entry line, in order, and fails if a key is duplicated.
+func DecodeSchemaKeys(t *testing.T, line string) []string {
+	keys := []string{}
+	values := map[string]stdjson.RawMessage{}
+
+	decoder := stdjson.NewDecoder(strings.NewReader(line))
+	_, err := decoder.Token()
+	if err != nil {
+		t.Fatalf("Unexpected error for '%s': %+v", line, err)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("Unexpected error for '%s': %+v", line, err)
+		}
+
+		key := token.(string)
+		if _, ok := values[key]; ok {
+			t.Fatalf("Duplicate key '%s' for '%s'", key, line)
+		}
+
+		value := stdjson.RawMessage{}
+		err = decoder.Decode(&value)
+		if err != nil {
+			t.Fatalf("Unexpected error for '%s': %+v", line, err)
+		}
+
+		values[key] = value
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Test protection of the keys used by every preset schema.
+func TestSchema_ProtectedKeys(t *testing.T) {
+	scenarios := []struct {
+		schema   *soba.Schema
+		expected []string
+	}{
+		{
+			schema: soba.DefaultSchema,
+			expected: []string{
+				"logger", "time", "level", "message",
+				"fields.logger", "fields.time", "fields.level", "fields.message",
+				"@timestamp", "log.level", "severity", "timestamp", "ecs.version", "host", "size",
+			},
+		},
+		{
+			schema: soba.ECSSchema,
+			expected: []string{
+				"log.logger", "@timestamp", "log.level", "message", "ecs.version",
+				"logger", "time", "level", "fields.message",
+				"fields.@timestamp", "fields.log.level", "severity", "timestamp", "fields.ecs.version", "host", "size",
+			},
+		},
+		{
+			schema: soba.GCPSchema,
+			expected: []string{
+				"logger", "timestamp", "severity", "message",
+				"fields.logger", "time", "level", "fields.message",
+				"@timestamp", "log.level", "fields.severity", "fields.timestamp", "ecs.version", "host", "size",
+			},
+		},
+		{
+			schema: soba.GELFSchema,
+			expected: []string{
+				"_logger", "timestamp", "level", "short_message", "version", "host",
+				"_fields.logger", "_time", "_level", "_message",
+				"_@timestamp", "_log.level", "_severity", "_timestamp", "_ecs.version", "_host", "_size",
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		appender := &SchemaAppender{name: "schema-log", schema: scenario.schema}
+		conf := &soba.Config{
+			Root: soba.ConfigLogger{
+				Level:     "info",
+				Appenders: []string{"schema-log"},
+			},
+			Keys: soba.ConfigKeys{
+				Protect: true,
+			},
+		}
+		registry := NewTestRegistry(t, conf, appender)
+
+		handler, err := soba.CreateWithRegistry(conf, registry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		logger := handler.New("foobar").With(
+			soba.String("logger", "api"), soba.String("time", "now"),
+			soba.String("level", "high"), soba.String("message", "hello"),
+		)
+		logger.Info("Cache is full",
+			soba.String("@timestamp", "now"), soba.String("log.level", "high"), soba.String("severity", "high"),
+			soba.String("timestamp", "now"), soba.String("ecs.version", "8.0"), soba.String("host", "api"),
+			soba.Int("size", 42),
+		)
+
+		if len(appender.entries) != 1 {
+			t.Fatalf("Unexpected number of entries for schema %s: %d should be %d", scenario.schema.Name,
+				len(appender.entries), 1)
+		}
+
+		keys := DecodeSchemaKeys(t, appender.entries[0])
+		if !reflect.DeepEqual(keys, scenario.expected) {
+			t.Fatalf("Unexpected keys for schema %s: %v should be %v", scenario.schema.Name, keys, scenario.expected)
+		}
+
+		err = handler.Close()
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+	}
+}
+
+// Test schema creation from a configuration.
+// nolint: gocyclo
+func TestSchema_New(t *testing.T) {
+	{
+		schema, err := soba.NewSchema(soba.ConfigSchema{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if schema != soba.DefaultSchema {
+			t.Fatalf("Unexpected schema: %+v", schema)
+		}
+	}
+	{
+		schema, err := soba.NewSchema(soba.ConfigSchema{Preset: soba.GCPSchemaName})
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if schema != soba.GCPSchema {
+			t.Fatalf("Unexpected schema: %+v", schema)
+		}
+	}
+	{
+		conf := soba.ConfigSchema{
+			MessageKey:  "msg",
+			OmitLogger:  true,
+			LevelFormat: "upper",
+			TimeFormat:  "unix",
+		}
+
+		schema, err := soba.NewSchema(conf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
+		other, err := soba.NewSchema(conf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		if schema != other {
+			t.Fatal("Schemas with the same configuration should be shared")
+		}
+
+		entry := soba.NewEntry("foobar", soba.InfoLevel, "User created", []soba.Field{
+			soba.String("id", "01CV5FN4JF1STZMYDJWMGQR68W"),
+		})
+		line := string(entry.EncodeWithSchema(soba.JSONEncoderType, schema))
+		entry.Flush()
+
+		expected := map[string]interface{}{
+			"level": "INFO",
+			"msg":   "User created",
+			"id":    "01CV5FN4JF1STZMYDJWMGQR68W",
+		}
+
+		values := DecodeSchemaEntry(t, line, soba.TimeKey, true)
+		if !reflect.DeepEqual(values, expected) {
+			t.Fatalf("Unexpected entry: %+v should be %+v", values, expected)
+		}
+
+		if soba.DefaultSchema.MessageKey != soba.MessageKey || soba.DefaultSchema.LoggerKey != soba.LoggerKey {
+			t.Fatalf("Default schema should not be modified: %+v", soba.DefaultSchema)
+		}
+	}
+
+	scenarios := []soba.ConfigSchema{
+		{Preset: "foobar"},
+		{LevelFormat: "title"},
+		{TimeFormat: "iso8601"},
+	}
+
+	for i, scenario := range scenarios {
+		schema, err := soba.NewSchema(scenario)
+		if err == nil {
+			t.Fatalf("An error was expected for scenario #%d", (i + 1))
+		}
+		if schema != nil {
+			t.Fatalf("Unexpected schema for scenario #%d", (i + 1))
+		}
+
+		conf := soba.NewDefaultConfig()
+		conf.Appenders["stdout"] = soba.ConfigAppender{
+			Type:   soba.ConsoleAppenderType,
+			Schema: scenario,
+		}
+
+		err = soba.ValidateConfig(conf)
+		if err == nil {
+			t.Fatalf("An error was expected for configuration of scenario #%d", (i + 1))
+		}
+	}
+}
+
+// Test schema with a field prefix, alongside a redactor and logger fields.
+func TestSchema_FieldPrefix(t *testing.T) {
+	appender := &SchemaAppender{
+		name:   "foobar",
+		schema: soba.GELFSchema,
+	}
+	defer CloseAppender(t, appender)
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender})
+	logger = logger.WithRedactor(NewTestRedactor(t))
+	logger = logger.With(soba.String("request_id", "a1b2c3"))
+
+	logger.Info("User logged in", soba.String("password", "123456"), soba.Bool("admin", false))
+	logger.Info("User logged out")
+
+	expected := []map[string]interface{}{
+		{
+			"_logger":       "foobar",
+			"level":         float64(6),
+			"short_message": "User logged in",
+			"version":       "1.1",
+			"_request_id":   "a1b2c3",
+			"_password":     "[REDACTED]",
+			"_admin":        false,
+		},
+		{
+			"_logger":       "foobar",
+			"level":         float64(6),
+			"short_message": "User logged out",
+			"version":       "1.1",
+			"_request_id":   "a1b2c3",
+		},
+	}
+
+	if len(appender.entries) != len(expected) {
+		t.Fatalf("Unexpected number of entries: %d should be %d", len(appender.entries), len(expected))
+	}
+
+	for i := range expected {
+		values := DecodeSchemaEntry(t, appender.entries[i], "timestamp", true)
+		delete(values, "host")
+
+		if !reflect.DeepEqual(values, expected[i]) {
+			t.Fatalf("Unexpected entry #%d: %+v should be %+v", i+1, values, expected[i])
+		}
+	}
+}
+
+// Test schema configuration from a configuration file.
+func TestSchema_ParseConfig(t *testing.T) {
+	path := "testdata/schema.yaml"
+	conf, err := soba.ParseConfig(path)
+	if err != nil {
+		t.Fatalf("Unexpected error for %s: %+v", path, err)
+	}
+
+	expected := map[string]soba.ConfigSchema{
+		"stdout": {
+			Preset: soba.ECSSchemaName,
+		},
+		"gelf": {
+			Preset:     soba.GELFSchemaName,
+			OmitLogger: true,
+		},
+		"custom": {
+			MessageKey:  "msg",
+			LevelFormat: "upper",
+			TimeFormat:  "unix",
+		},
+	}
+
+	for name, schema := range expected {
+		if conf.Appenders[name].Schema != schema {
+			t.Fatalf("Unexpected schema for appender %s: %+v should be %+v", name,
+				conf.Appenders[name].Schema, schema)
+		}
+	}
+
+	for _, format := range []soba.LevelFormat{soba.LowerLevelFormat, soba.UpperLevelFormat, soba.SyslogLevelFormat} {
+		parsed, ok := soba.ParseLevelFormat(format.String())
+		if !ok || parsed != format {
+			t.Fatalf("Unexpected level format: %s", format)
+		}
+	}
+
+	for _, format := range []soba.TimeFormat{soba.RFC3339TimeFormat, soba.UnixTimeFormat} {
+		parsed, ok := soba.ParseTimeFormat(format.String())
+		if !ok || parsed != format {
+			t.Fatalf("Unexpected time format: %s", format)
+		}
+	}
+}
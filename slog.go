@@ -0,0 +1,257 @@
+//go:build go1.21
+// +build go1.21
+
+package soba
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogOptions defines how a SlogHandler routes records to soba loggers.
+type SlogOptions struct {
+	// Name is the name of the logger used by default. If it's empty, the root logger is used.
+	Name string
+	// LoggerKey is the attribute key used to select a logger by its name.
+	// If it's empty, every record is routed to the default logger.
+	LoggerKey string
+}
+
+// SlogHandler is a slog.Handler that writes records using soba loggers: they obey to the loggers hierarchy,
+// appenders and levels defined by the handler configuration.
+//
+// A record is routed to the logger identified by the attribute with the LoggerKey option, either defined on the
+// record or with WithAttrs. Otherwise, the default logger is used.
+//
+// Since the attributes of a record are unknown to Enabled, it reports whether the most verbose logger of the
+// handler handles a level when the LoggerKey option is defined. Handle then verifies the level of the logger the
+// record is routed to.
+type SlogHandler struct {
+	handler Handler
+	logger  Logger
+	key     string
+	// verbose is the most verbose level of the handler loggers, if a record could be routed with its attributes.
+	verbose Level
+	fields  []Field
+	groups  []slogGroup
+}
+
+// slogGroup is a group opened with WithGroup, with the fields added with WithAttrs afterward.
+type slogGroup struct {
+	name   string
+	fields []Field
+}
+
+// NewSlogHandler creates a new slog.Handler using given handler and options.
+func NewSlogHandler(handler Handler, options SlogOptions) *SlogHandler {
+	other := &SlogHandler{
+		handler: handler,
+		logger:  getSlogLogger(handler, options.Name),
+		key:     options.LoggerKey,
+	}
+
+	if other.key != "" {
+		for _, logger := range handler.Loggers() {
+			if logger.Level != NoLevel && logger.Level > other.verbose {
+				other.verbose = logger.Level
+			}
+		}
+	}
+
+	return other
+}
+
+// Enabled reports whether the handler handles records at given level.
+func (handler *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if handler.logger.Enabled(getSlogLevel(level)) {
+		return true
+	}
+
+	// A record could be routed to a more verbose logger with its attributes.
+	return handler.key != "" && handler.verbose >= getSlogLevel(level)
+}
+
+// Handle writes given record using its logger.
+func (handler *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	logger := handler.logger
+	fields := make([]Field, 0, record.NumAttrs())
+
+	record.Attrs(func(attr slog.Attr) bool {
+		next, ok := handler.getLogger(attr)
+		if ok && len(handler.groups) == 0 {
+			logger = next
+			return true
+		}
+		fields = appendSlogAttr(fields, attr)
+		return true
+	})
+
+	if !logger.Enabled(getSlogLevel(record.Level)) {
+		return nil
+	}
+
+	for i := len(handler.groups) - 1; i >= 0; i-- {
+		group := handler.groups[i]
+		list := make([]Field, 0, len(group.fields)+len(fields))
+		list = append(list, group.fields...)
+		list = append(list, fields...)
+		fields = fields[:0:0]
+		if len(list) > 0 {
+			fields = append(fields, Group(group.name, list...))
+		}
+	}
+
+	list := make([]Field, 0, len(handler.fields)+len(fields))
+	list = append(list, handler.fields...)
+	list = append(list, fields...)
+
+	// A record could be produced asynchronously, or replayed: its own time is used, unless it's undefined.
+	now := record.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	logger.writeAt(now.Unix(), getSlogLevel(record.Level), record.Message, list)
+
+	return nil
+}
+
+// WithAttrs returns a new handler whose records will include given attributes.
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return handler
+	}
+
+	other := handler.copy()
+	fields := []Field{}
+
+	for _, attr := range attrs {
+		next, ok := other.getLogger(attr)
+		if ok && len(other.groups) == 0 {
+			other.logger = next
+			continue
+		}
+		fields = appendSlogAttr(fields, attr)
+	}
+
+	if len(other.groups) == 0 {
+		other.fields = append(other.fields, fields...)
+		return other
+	}
+
+	last := len(other.groups) - 1
+	group := other.groups[last]
+	group.fields = append(group.fields[:len(group.fields):len(group.fields)], fields...)
+	other.groups[last] = group
+
+	return other
+}
+
+// WithGroup returns a new handler whose following attributes will be nested in a group with given name.
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+
+	other := handler.copy()
+	other.groups = append(other.groups, slogGroup{name: name})
+
+	return other
+}
+
+// getLogger returns the logger identified by given attribute, if it's the attribute with the LoggerKey option
+// and if its value is a valid logger name.
+func (handler *SlogHandler) getLogger(attr slog.Attr) (Logger, bool) {
+	if handler.key == "" || attr.Key != handler.key {
+		return Logger{}, false
+	}
+
+	name := attr.Value.Resolve().String()
+	if !IsLoggerNameValid(name) {
+		return Logger{}, false
+	}
+
+	return handler.handler.New(name), true
+}
+
+func (handler *SlogHandler) copy() *SlogHandler {
+	other := &SlogHandler{
+		handler: handler.handler,
+		logger:  handler.logger,
+		key:     handler.key,
+		verbose: handler.verbose,
+	}
+
+	other.fields = make([]Field, len(handler.fields))
+	copy(other.fields, handler.fields)
+
+	other.groups = make([]slogGroup, len(handler.groups))
+	copy(other.groups, handler.groups)
+
+	return other
+}
+
+// getSlogLogger returns the logger of given handler identified by given name, or its root logger if the name is
+// empty.
+func getSlogLogger(source Handler, name string) Logger {
+	if name != "" {
+		return source.New(name)
+	}
+
+	return source.Root()
+}
+
+// getSlogLevel returns the soba level of given slog level.
+func getSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// appendSlogAttr converts given attribute to a field and appends it to the list.
+// As defined by slog.Handler, empty attributes and empty groups are ignored, and a group without key is inlined.
+func appendSlogAttr(fields []Field, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindBool:
+		return append(fields, Bool(attr.Key, attr.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, Duration(attr.Key, attr.Value.Duration()))
+	case slog.KindFloat64:
+		return append(fields, Float64(attr.Key, attr.Value.Float64()))
+	case slog.KindInt64:
+		return append(fields, Int64(attr.Key, attr.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, Uint64(attr.Key, attr.Value.Uint64()))
+	case slog.KindString:
+		return append(fields, String(attr.Key, attr.Value.String()))
+	case slog.KindTime:
+		return append(fields, Time(attr.Key, attr.Value.Time()))
+	case slog.KindGroup:
+		group := []Field{}
+		for _, nested := range attr.Value.Group() {
+			group = appendSlogAttr(group, nested)
+		}
+		if attr.Key == "" {
+			return append(fields, group...)
+		}
+		if len(group) == 0 {
+			return fields
+		}
+		return append(fields, Group(attr.Key, group...))
+	default:
+		return append(fields, Any(attr.Key, attr.Value.Any()))
+	}
+}
@@ -0,0 +1,196 @@
+//go:build go1.21
+// +build go1.21
+
+package soba_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/novln/soba"
+)
+
+// TestToken is a slog.LogValuer for test.
+type TestToken string
+
+// LogValue returns a redacted value of the token.
+func (token TestToken) LogValue() slog.Value {
+	return slog.StringValue("token:" + string(token)[:3])
+}
+
+// Test slog handler with soba loggers.
+// nolint: gocyclo
+func TestSlogHandler(t *testing.T) {
+	appender := NewTestAppender("slog-log")
+	defer CloseAppender(t, appender)
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level: "info",
+			Appenders: []string{
+				"slog-log",
+			},
+		},
+		Appenders: map[string]soba.ConfigAppender{},
+		Loggers: map[string]soba.ConfigLogger{
+			"vendor.sql": {
+				Level: "debug",
+				Appenders: []string{
+					"slog-log",
+				},
+			},
+			"vendor.http": {
+				Level:     "error",
+				Appenders: []string{},
+			},
+		},
+	}
+	registry := NewTestRegistry(t, conf, appender)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	logger := slog.New(soba.NewSlogHandler(handler, soba.SlogOptions{
+		Name:      "vendor",
+		LoggerKey: "component",
+	}))
+
+	logger.Debug("Cache miss", "key", "users")
+	logger.Info("Request received", "method", "GET", "status", 200, "elapsed", 3*time.Millisecond,
+		slog.Group("user", "id", uint64(42), "admin", true), slog.Group("empty"), "token", TestToken("secret"))
+	logger.With("component", "vendor.sql").Debug("Query executed", "rows", 3)
+	logger.Debug("Query executed", "component", "vendor.sql", "rows", 1)
+	logger.Warn("Request timeout", "component", "vendor.http")
+	logger.With("request_id", "f29e7b").WithGroup("db").With("name", "users").
+		WithGroup("query").Info("Query executed", "rows", 5, "ratio", 0.5)
+	logger.Error("Connection refused", slog.Group("", "retry", false))
+
+	// Without a name, records are written by the root logger, and they are never routed with their attributes.
+	root := slog.New(soba.NewSlogHandler(handler, soba.SlogOptions{}))
+	root.Debug("Cache miss", "component", "vendor.sql")
+	root.Info("Cache warmed", "component", "vendor.sql")
+
+	if !logger.Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatal("Info level should be enabled")
+	}
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Debug level should be enabled, since a record could be routed to vendor.sql")
+	}
+	if !logger.With("component", "vendor.sql").Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Debug level should be enabled")
+	}
+	if root.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Debug level should be disabled")
+	}
+
+	expected := []string{
+		fmt.Sprint(
+			`{"logger":"vendor","level":"info","message":"Request received","method":"GET","status":200,`,
+			`"elapsed":"3ms","user":{"id":42,"admin":true},"token":"token:sec"}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"vendor.sql","level":"debug","message":"Query executed","rows":3}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"vendor.sql","level":"debug","message":"Query executed","rows":1}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"vendor","level":"info","message":"Query executed","request_id":"f29e7b",`,
+			`"db":{"name":"users","query":{"rows":5,"ratio":0.5}}}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"vendor","level":"error","message":"Connection refused","retry":false}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"root","level":"info","message":"Cache warmed","component":"vendor.sql"}`,
+			"\n",
+		),
+	}
+
+	if appender.Size() != len(expected) {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Size(), len(expected))
+	}
+
+	for i := range expected {
+		if appender.Log(i) != expected[i] {
+			t.Fatalf("Unexpected log message #%d: '%s' should be '%s'", i+1, appender.Log(i), expected[i])
+		}
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+// WrappedHandler is a soba.Handler that isn't created by soba, such as a test double.
+type WrappedHandler struct {
+	soba.Handler
+}
+
+// Test slog handler with the time of records, and with a handler that isn't created by soba.
+func TestSlogHandler_Record(t *testing.T) {
+	appender := NewTestAppender("slog-log")
+	defer CloseAppender(t, appender)
+
+	conf := &soba.Config{
+		Root: soba.ConfigLogger{
+			Level: "info",
+			Appenders: []string{
+				"slog-log",
+			},
+		},
+		Appenders: map[string]soba.ConfigAppender{},
+		Loggers:   map[string]soba.ConfigLogger{},
+	}
+	registry := NewTestRegistry(t, conf, appender)
+
+	handler, err := soba.CreateWithRegistry(conf, registry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	source := soba.NewSlogHandler(WrappedHandler{Handler: handler}, soba.SlogOptions{})
+
+	replayed := time.Date(2019, 4, 20, 9, 53, 13, 0, time.UTC)
+	err = source.Handle(context.Background(), slog.NewRecord(replayed, slog.LevelInfo, "Request replayed", 0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	start := time.Now().Add(-time.Second)
+	err = source.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "Request received", 0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	if appender.Size() != 2 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Size(), 2)
+	}
+
+	expected := fmt.Sprint(`{"logger":"root","level":"info","message":"Request replayed"}`, "\n")
+	if appender.Log(0) != expected {
+		t.Fatalf("Unexpected log message #1: '%s' should be '%s'", appender.Log(0), expected)
+	}
+	if !appender.Time(0).Equal(replayed) {
+		t.Fatalf("Unexpected time for log message #1: %s should be %s", appender.Time(0), replayed)
+	}
+	if appender.Time(1).Before(start.Truncate(time.Second)) {
+		t.Fatalf("Unexpected time for log message #2: %s should be after %s", appender.Time(1), start)
+	}
+
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
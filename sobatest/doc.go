@@ -0,0 +1,5 @@
+// Package sobatest provides appenders and helpers to test code that uses soba.
+//
+// An ObservedAppender records every entry with its decoded fields, so a test can assert on them without parsing
+// an encoded output. A TestingAppender writes every entry with the logging function of a testing.TB.
+package sobatest
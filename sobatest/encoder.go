@@ -0,0 +1,357 @@
+package sobatest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/novln/soba"
+)
+
+// mapEncoder is an Encoder that decodes fields to Go values: an object is decoded as a map[string]interface{}, and
+// an array as a []interface{}. Other values are kept with their type, such as int64, string or time.Duration.
+type mapEncoder struct {
+	object map[string]interface{}
+	array  []interface{}
+}
+
+// newMapEncoder creates a new mapEncoder.
+func newMapEncoder() *mapEncoder {
+	return &mapEncoder{
+		object: map[string]interface{}{},
+		array:  []interface{}{},
+	}
+}
+
+// decodeObject returns the properties registered by given object.
+func decodeObject(value soba.ObjectMarshaler) map[string]interface{} {
+	encoder := newMapEncoder()
+	value.Encode(encoder)
+	return encoder.object
+}
+
+// decodeArray returns the elements registered by given array.
+func decodeArray(value soba.ArrayMarshaler) []interface{} {
+	encoder := newMapEncoder()
+	value.Encode(encoder)
+	return encoder.array
+}
+
+// Bytes returns nil since the content is decoded.
+func (encoder *mapEncoder) Bytes() []byte {
+	return nil
+}
+
+// Close is a no-op.
+func (encoder *mapEncoder) Close() {}
+
+// Encode executes given callback with the encoder and returns nil since the content is decoded.
+func (encoder *mapEncoder) Encode(handler func(encoder soba.Encoder)) []byte {
+	handler(encoder)
+	return nil
+}
+
+// AddArray adds the field key with given ArrayMarshaler to the decoded object.
+func (encoder *mapEncoder) AddArray(key string, value soba.ArrayMarshaler) {
+	encoder.object[key] = decodeArray(value)
+}
+
+// AddObject adds the field key with given ObjectMarshaler to the decoded object.
+func (encoder *mapEncoder) AddObject(key string, value soba.ObjectMarshaler) {
+	encoder.object[key] = decodeObject(value)
+}
+
+// AddObjects adds the field key with given list of ObjectMarshaler, decoded as objects, to the decoded object.
+func (encoder *mapEncoder) AddObjects(key string, values []soba.ObjectMarshaler) {
+	list := make([]interface{}, 0, len(values))
+	for i := range values {
+		list = append(list, decodeObject(values[i]))
+	}
+	encoder.object[key] = list
+}
+
+// AddInt adds the field key with given integer to the decoded object.
+func (encoder *mapEncoder) AddInt(key string, value int) {
+	encoder.object[key] = value
+}
+
+// AddInts adds the field key with a copy of given list of integer to the decoded object.
+func (encoder *mapEncoder) AddInts(key string, values []int) {
+	encoder.object[key] = append([]int{}, values...)
+}
+
+// AddInt8 adds the field key with given integer to the decoded object.
+func (encoder *mapEncoder) AddInt8(key string, value int8) {
+	encoder.object[key] = value
+}
+
+// AddInt8s adds the field key with a copy of given list of integer to the decoded object.
+func (encoder *mapEncoder) AddInt8s(key string, values []int8) {
+	encoder.object[key] = append([]int8{}, values...)
+}
+
+// AddInt16 adds the field key with given integer to the decoded object.
+func (encoder *mapEncoder) AddInt16(key string, value int16) {
+	encoder.object[key] = value
+}
+
+// AddInt16s adds the field key with a copy of given list of integer to the decoded object.
+func (encoder *mapEncoder) AddInt16s(key string, values []int16) {
+	encoder.object[key] = append([]int16{}, values...)
+}
+
+// AddInt32 adds the field key with given integer to the decoded object.
+func (encoder *mapEncoder) AddInt32(key string, value int32) {
+	encoder.object[key] = value
+}
+
+// AddInt32s adds the field key with a copy of given list of integer to the decoded object.
+func (encoder *mapEncoder) AddInt32s(key string, values []int32) {
+	encoder.object[key] = append([]int32{}, values...)
+}
+
+// AddInt64 adds the field key with given integer to the decoded object.
+func (encoder *mapEncoder) AddInt64(key string, value int64) {
+	encoder.object[key] = value
+}
+
+// AddInt64s adds the field key with a copy of given list of integer to the decoded object.
+func (encoder *mapEncoder) AddInt64s(key string, values []int64) {
+	encoder.object[key] = append([]int64{}, values...)
+}
+
+// AddUint adds the field key with given unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint(key string, value uint) {
+	encoder.object[key] = value
+}
+
+// AddUints adds the field key with a copy of given list of unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUints(key string, values []uint) {
+	encoder.object[key] = append([]uint{}, values...)
+}
+
+// AddUint8 adds the field key with given unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint8(key string, value uint8) {
+	encoder.object[key] = value
+}
+
+// AddUint8s adds the field key with a copy of given list of unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint8s(key string, values []uint8) {
+	encoder.object[key] = append([]uint8{}, values...)
+}
+
+// AddUint16 adds the field key with given unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint16(key string, value uint16) {
+	encoder.object[key] = value
+}
+
+// AddUint16s adds the field key with a copy of given list of unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint16s(key string, values []uint16) {
+	encoder.object[key] = append([]uint16{}, values...)
+}
+
+// AddUint32 adds the field key with given unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint32(key string, value uint32) {
+	encoder.object[key] = value
+}
+
+// AddUint32s adds the field key with a copy of given list of unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint32s(key string, values []uint32) {
+	encoder.object[key] = append([]uint32{}, values...)
+}
+
+// AddUint64 adds the field key with given unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint64(key string, value uint64) {
+	encoder.object[key] = value
+}
+
+// AddUint64s adds the field key with a copy of given list of unsigned integer to the decoded object.
+func (encoder *mapEncoder) AddUint64s(key string, values []uint64) {
+	encoder.object[key] = append([]uint64{}, values...)
+}
+
+// AddFloat32 adds the field key with given number to the decoded object.
+func (encoder *mapEncoder) AddFloat32(key string, value float32) {
+	encoder.object[key] = value
+}
+
+// AddFloat32s adds the field key with a copy of given list of number to the decoded object.
+func (encoder *mapEncoder) AddFloat32s(key string, values []float32) {
+	encoder.object[key] = append([]float32{}, values...)
+}
+
+// AddFloat64 adds the field key with given number to the decoded object.
+func (encoder *mapEncoder) AddFloat64(key string, value float64) {
+	encoder.object[key] = value
+}
+
+// AddFloat64s adds the field key with a copy of given list of number to the decoded object.
+func (encoder *mapEncoder) AddFloat64s(key string, values []float64) {
+	encoder.object[key] = append([]float64{}, values...)
+}
+
+// AddString adds the field key with given string to the decoded object.
+func (encoder *mapEncoder) AddString(key string, value string) {
+	encoder.object[key] = value
+}
+
+// AddStrings adds the field key with a copy of given list of string to the decoded object.
+func (encoder *mapEncoder) AddStrings(key string, values []string) {
+	encoder.object[key] = append([]string{}, values...)
+}
+
+// AddStringer adds the field key with given Stringer to the decoded object.
+func (encoder *mapEncoder) AddStringer(key string, value fmt.Stringer) {
+	encoder.object[key] = value.String()
+}
+
+// AddStringers adds the field key with given list of Stringer, as strings, to the decoded object.
+func (encoder *mapEncoder) AddStringers(key string, values []fmt.Stringer) {
+	list := make([]string, 0, len(values))
+	for i := range values {
+		list = append(list, values[i].String())
+	}
+	encoder.object[key] = list
+}
+
+// AddTime adds the field key with given Time to the decoded object.
+func (encoder *mapEncoder) AddTime(key string, value time.Time) {
+	encoder.object[key] = value
+}
+
+// AddTimes adds the field key with a copy of given list of Time to the decoded object.
+func (encoder *mapEncoder) AddTimes(key string, values []time.Time) {
+	encoder.object[key] = append([]time.Time{}, values...)
+}
+
+// AddDuration adds the field key with given Duration to the decoded object.
+func (encoder *mapEncoder) AddDuration(key string, value time.Duration) {
+	encoder.object[key] = value
+}
+
+// AddDurations adds the field key with a copy of given list of Duration to the decoded object.
+func (encoder *mapEncoder) AddDurations(key string, values []time.Duration) {
+	encoder.object[key] = append([]time.Duration{}, values...)
+}
+
+// AddBool adds the field key with given boolean to the decoded object.
+func (encoder *mapEncoder) AddBool(key string, value bool) {
+	encoder.object[key] = value
+}
+
+// AddBools adds the field key with a copy of given list of boolean to the decoded object.
+func (encoder *mapEncoder) AddBools(key string, values []bool) {
+	encoder.object[key] = append([]bool{}, values...)
+}
+
+// AddBinary adds the field key with a copy of given buffer or bytes to the decoded object.
+func (encoder *mapEncoder) AddBinary(key string, value []byte) {
+	encoder.object[key] = append([]byte{}, value...)
+}
+
+// AddNull adds the field key with a nil value to the decoded object.
+func (encoder *mapEncoder) AddNull(key string) {
+	encoder.object[key] = nil
+}
+
+// AppendArray decodes the input array marshaler and appends its elements, as a list, to the decoded array.
+func (encoder *mapEncoder) AppendArray(value soba.ArrayMarshaler) {
+	encoder.array = append(encoder.array, decodeArray(value))
+}
+
+// AppendObject decodes the input object marshaler and appends its properties to the decoded array.
+func (encoder *mapEncoder) AppendObject(value soba.ObjectMarshaler) {
+	encoder.array = append(encoder.array, decodeObject(value))
+}
+
+// AppendInt appends the input integer to the decoded array.
+func (encoder *mapEncoder) AppendInt(value int) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendInt8 appends the input integer to the decoded array.
+func (encoder *mapEncoder) AppendInt8(value int8) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendInt16 appends the input integer to the decoded array.
+func (encoder *mapEncoder) AppendInt16(value int16) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendInt32 appends the input integer to the decoded array.
+func (encoder *mapEncoder) AppendInt32(value int32) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendInt64 appends the input integer to the decoded array.
+func (encoder *mapEncoder) AppendInt64(value int64) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendUint appends the input unsigned integer to the decoded array.
+func (encoder *mapEncoder) AppendUint(value uint) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendUint8 appends the input unsigned integer to the decoded array.
+func (encoder *mapEncoder) AppendUint8(value uint8) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendUint16 appends the input unsigned integer to the decoded array.
+func (encoder *mapEncoder) AppendUint16(value uint16) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendUint32 appends the input unsigned integer to the decoded array.
+func (encoder *mapEncoder) AppendUint32(value uint32) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendUint64 appends the input unsigned integer to the decoded array.
+func (encoder *mapEncoder) AppendUint64(value uint64) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendFloat32 appends the input number to the decoded array.
+func (encoder *mapEncoder) AppendFloat32(value float32) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendFloat64 appends the input number to the decoded array.
+func (encoder *mapEncoder) AppendFloat64(value float64) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendString appends the input string to the decoded array.
+func (encoder *mapEncoder) AppendString(value string) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendTime appends the input Time to the decoded array.
+func (encoder *mapEncoder) AppendTime(value time.Time) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendDuration appends the input Duration to the decoded array.
+func (encoder *mapEncoder) AppendDuration(value time.Duration) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendBool appends the input boolean to the decoded array.
+func (encoder *mapEncoder) AppendBool(value bool) {
+	encoder.array = append(encoder.array, value)
+}
+
+// AppendBinary appends a copy of the input buffer or bytes to the decoded array.
+func (encoder *mapEncoder) AppendBinary(value []byte) {
+	encoder.array = append(encoder.array, append([]byte{}, value...))
+}
+
+// AppendNull appends a nil value to the decoded array.
+func (encoder *mapEncoder) AppendNull() {
+	encoder.array = append(encoder.array, nil)
+}
+
+// Ensure mapEncoder implements Encoder interface at compile time.
+var _ soba.Encoder = &mapEncoder{}
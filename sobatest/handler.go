@@ -0,0 +1,90 @@
+package sobatest
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+// DefaultLoggerName is the name of a logger created by NewLogger or NewTestingLogger.
+const DefaultLoggerName = "test"
+
+// appenderID is used to generate a unique appender name.
+var appenderID = uint64(0)
+
+// ObservedAppenderType is the appender type used by a handler created with NewHandlerWithConfig to record its
+// entries.
+const ObservedAppenderType = "sobatest"
+
+// nextAppenderName returns a unique appender name.
+func nextAppenderName() string {
+	return fmt.Sprintf("sobatest-%d", atomic.AddUint64(&appenderID, 1))
+}
+
+// NewLogger creates a new logger with given level that records its entries with an ObservedAppender.
+func NewLogger(level soba.Level) (soba.Logger, *ObservedAppender) {
+	appender := NewObservedAppender(nextAppenderName())
+	logger := soba.NewLogger(DefaultLoggerName, level, []soba.Appender{appender})
+	return logger, appender
+}
+
+// NewHandler creates a new handler with a root logger using given level, whose loggers record their entries
+// with an ObservedAppender. The handler is closed when the test and its subtests complete.
+func NewHandler(tb testing.TB, level soba.Level) (soba.Handler, *ObservedAppender) {
+	tb.Helper()
+
+	return NewHandlerWithConfig(tb, &soba.Config{
+		Root: soba.ConfigLogger{
+			Level: level.String(),
+		},
+		Appenders: map[string]soba.ConfigAppender{},
+		Loggers:   map[string]soba.ConfigLogger{},
+	})
+}
+
+// NewHandlerWithConfig creates a new handler using given configuration, whose root logger and configured loggers
+// also record their entries with an ObservedAppender. The handler is closed when the test and its subtests
+// complete.
+func NewHandlerWithConfig(tb testing.TB, conf *soba.Config) (soba.Handler, *ObservedAppender) {
+	tb.Helper()
+
+	appender := NewObservedAppender(nextAppenderName())
+	registry := soba.NewRegistry()
+	err := registry.RegisterAppenderType(ObservedAppenderType, func(string, soba.ConfigAppender) (soba.Appender, error) {
+		return appender, nil
+	})
+	if err != nil {
+		tb.Fatalf("Cannot register observed appender: %+v", err)
+	}
+
+	other := *conf
+	other.Appenders = make(map[string]soba.ConfigAppender, len(conf.Appenders)+1)
+	for name, appender := range conf.Appenders {
+		other.Appenders[name] = appender
+	}
+	other.Appenders[appender.Name()] = soba.ConfigAppender{
+		Type: ObservedAppenderType,
+	}
+	other.Root.Appenders = append(append([]string{}, conf.Root.Appenders...), appender.Name())
+	other.Loggers = make(map[string]soba.ConfigLogger, len(conf.Loggers))
+	for name, logger := range conf.Loggers {
+		logger.Appenders = append(append([]string{}, logger.Appenders...), appender.Name())
+		other.Loggers[name] = logger
+	}
+
+	handler, err := soba.CreateWithRegistry(&other, registry)
+	if err != nil {
+		tb.Fatalf("Cannot create handler: %+v", err)
+	}
+
+	tb.Cleanup(func() {
+		err := handler.Close()
+		if err != nil {
+			tb.Errorf("Cannot close handler: %+v", err)
+		}
+	})
+
+	return handler, appender
+}
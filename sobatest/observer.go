@@ -0,0 +1,196 @@
+package sobatest
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/novln/soba"
+)
+
+// LoggedEntry is an entry recorded by an ObservedAppender, with its decoded fields.
+type LoggedEntry struct {
+	Name    string
+	Level   soba.Level
+	Message string
+	Time    time.Time
+	Fields  map[string]interface{}
+}
+
+// Entries is a list of recorded entries.
+type Entries []LoggedEntry
+
+// Len returns the number of entries.
+func (entries Entries) Len() int {
+	return len(entries)
+}
+
+// AllUntimed returns a copy of the entries with a zero Time, which is convenient for comparison.
+func (entries Entries) AllUntimed() Entries {
+	list := make(Entries, 0, len(entries))
+	for _, entry := range entries {
+		entry.Time = time.Time{}
+		list = append(list, entry)
+	}
+	return list
+}
+
+// FilterMessage returns the entries with given message.
+func (entries Entries) FilterMessage(message string) Entries {
+	return entries.filter(func(entry LoggedEntry) bool {
+		return entry.Message == message
+	})
+}
+
+// FilterLevel returns the entries with given level.
+func (entries Entries) FilterLevel(level soba.Level) Entries {
+	return entries.filter(func(entry LoggedEntry) bool {
+		return entry.Level == level
+	})
+}
+
+// FilterLoggerName returns the entries written by the logger with given name.
+func (entries Entries) FilterLoggerName(name string) Entries {
+	return entries.filter(func(entry LoggedEntry) bool {
+		return entry.Name == name
+	})
+}
+
+// FilterField returns the entries with a field that has the same key and value than given field.
+func (entries Entries) FilterField(field soba.Field) Entries {
+	expected := DecodeFields(field)
+	return entries.filter(func(entry LoggedEntry) bool {
+		for key, value := range expected {
+			current, ok := entry.Fields[key]
+			if !ok || !reflect.DeepEqual(current, value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// FilterFieldKey returns the entries with a field with given key.
+func (entries Entries) FilterFieldKey(key string) Entries {
+	return entries.filter(func(entry LoggedEntry) bool {
+		_, ok := entry.Fields[key]
+		return ok
+	})
+}
+
+// filter returns the entries that match given predicate.
+func (entries Entries) filter(predicate func(entry LoggedEntry) bool) Entries {
+	list := Entries{}
+	for _, entry := range entries {
+		if predicate(entry) {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// DecodeFields returns the values of given fields, as they are recorded by an ObservedAppender.
+func DecodeFields(fields ...soba.Field) map[string]interface{} {
+	encoder := newMapEncoder()
+	for _, field := range fields {
+		field.Write(encoder)
+	}
+	return encoder.object
+}
+
+// ObservedAppender is an appender that records every entry in memory, with its decoded fields.
+// It's safe for concurrent use.
+type ObservedAppender struct {
+	mutex   sync.Mutex
+	name    string
+	entries Entries
+}
+
+// NewObservedAppender creates a new ObservedAppender.
+func NewObservedAppender(name string) *ObservedAppender {
+	return &ObservedAppender{
+		name:    name,
+		entries: Entries{},
+	}
+}
+
+// Name returns appender name.
+func (appender *ObservedAppender) Name() string {
+	return appender.name
+}
+
+// Close recycles underlying resources of appender.
+func (appender *ObservedAppender) Close() error {
+	return nil
+}
+
+// Write receives a log entry and records it.
+// If the entry has a redactor, it's applied on the message and the fields.
+func (appender *ObservedAppender) Write(entry *soba.Entry) {
+	encoder := newMapEncoder()
+	fields := entry.Redactor().Encoder(encoder)
+	for _, field := range entry.Fields() {
+		field.Write(fields)
+	}
+
+	logged := LoggedEntry{
+		Name:    entry.Name(),
+		Level:   entry.Level(),
+		Message: entry.Redactor().String(entry.Message()),
+		Time:    time.Unix(entry.Unix(), 0).UTC(),
+		Fields:  encoder.object,
+	}
+
+	appender.mutex.Lock()
+	defer appender.mutex.Unlock()
+
+	appender.entries = append(appender.entries, logged)
+}
+
+// Len returns the number of recorded entries.
+func (appender *ObservedAppender) Len() int {
+	appender.mutex.Lock()
+	defer appender.mutex.Unlock()
+
+	return len(appender.entries)
+}
+
+// All returns a copy of the recorded entries.
+func (appender *ObservedAppender) All() Entries {
+	appender.mutex.Lock()
+	defer appender.mutex.Unlock()
+
+	list := make(Entries, len(appender.entries))
+	copy(list, appender.entries)
+
+	return list
+}
+
+// AllUntimed returns a copy of the recorded entries with a zero Time, which is convenient for comparison.
+func (appender *ObservedAppender) AllUntimed() Entries {
+	return appender.All().AllUntimed()
+}
+
+// TakeAll returns the recorded entries and clears them.
+func (appender *ObservedAppender) TakeAll() Entries {
+	appender.mutex.Lock()
+	defer appender.mutex.Unlock()
+
+	list := appender.entries
+	appender.entries = Entries{}
+
+	return list
+}
+
+// FilterMessage returns the recorded entries with given message.
+func (appender *ObservedAppender) FilterMessage(message string) Entries {
+	return appender.All().FilterMessage(message)
+}
+
+// FilterField returns the recorded entries with a field that has the same key and value than given field.
+func (appender *ObservedAppender) FilterField(field soba.Field) Entries {
+	return appender.All().FilterField(field)
+}
+
+// Ensure ObservedAppender implements Appender interface at compile time.
+var _ soba.Appender = &ObservedAppender{}
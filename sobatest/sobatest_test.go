@@ -0,0 +1,126 @@
+package sobatest_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/novln/soba"
+	"github.com/novln/soba/sobatest"
+)
+
+// Test observed appender with a logger.
+func TestObservedAppender(t *testing.T) {
+	logger, appender := sobatest.NewLogger(soba.InfoLevel)
+
+	logger = logger.With(soba.String("service", "api"))
+	logger.Debug("Cache updated", soba.Int("size", 42))
+	logger.Info("User created", soba.Int64("id", 7), soba.Strings("roles", []string{"admin"}))
+	logger.Warn("Request timeout", soba.Duration("elapsed", 3*time.Second), soba.Group("user", soba.Int64("id", 7)))
+	logger.Error("Request failed", soba.Error(errors.New("timeout")))
+
+	if appender.Len() != 3 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Len(), 3)
+	}
+
+	expected := sobatest.Entries{
+		{
+			Name:    "test",
+			Level:   soba.InfoLevel,
+			Message: "User created",
+			Fields: map[string]interface{}{
+				"service": "api",
+				"id":      int64(7),
+				"roles":   []string{"admin"},
+			},
+		},
+		{
+			Name:    "test",
+			Level:   soba.WarnLevel,
+			Message: "Request timeout",
+			Fields: map[string]interface{}{
+				"service": "api",
+				"elapsed": 3 * time.Second,
+				"user": map[string]interface{}{
+					"id": int64(7),
+				},
+			},
+		},
+		{
+			Name:    "test",
+			Level:   soba.ErrorLevel,
+			Message: "Request failed",
+			Fields: map[string]interface{}{
+				"service": "api",
+				"error": map[string]interface{}{
+					"message": "timeout",
+					"type":    "*errors.errorString",
+				},
+			},
+		},
+	}
+
+	entries := appender.AllUntimed()
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("Unexpected entries: %+v should be %+v", entries, expected)
+	}
+
+	if appender.All()[0].Time.IsZero() {
+		t.Fatal("Entry time should be defined")
+	}
+
+	if appender.FilterMessage("User created").Len() != 1 {
+		t.Fatal("An entry was expected with message")
+	}
+	if appender.FilterField(soba.String("service", "api")).Len() != 3 {
+		t.Fatal("Three entries were expected with field")
+	}
+	if appender.FilterField(soba.Int64("id", 8)).Len() != 0 {
+		t.Fatal("No entry was expected with field")
+	}
+	if appender.All().FilterLevel(soba.WarnLevel).FilterFieldKey("elapsed").Len() != 1 {
+		t.Fatal("An entry was expected with level and field key")
+	}
+
+	if appender.TakeAll().Len() != 3 || appender.Len() != 0 {
+		t.Fatal("Entries should be cleared")
+	}
+}
+
+// Test observed appender with a handler.
+func TestObservedAppender_Handler(t *testing.T) {
+	handler, appender := sobatest.NewHandlerWithConfig(t, &soba.Config{
+		Root: soba.ConfigLogger{
+			Level: "warning",
+		},
+		Appenders: map[string]soba.ConfigAppender{},
+		Loggers: map[string]soba.ConfigLogger{
+			"repositories": {
+				Level: "debug",
+			},
+		},
+		Redact: soba.ConfigRedact{
+			Keys: []soba.ConfigRedactKey{
+				{Pattern: "password"},
+			},
+		},
+	})
+
+	handler.New("repositories.users").Debug("User created", soba.String("password", "123456"))
+	handler.New("services.users").Info("User created")
+
+	entries := appender.All().FilterLoggerName("repositories.users")
+	if entries.Len() != 1 || appender.Len() != 1 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Len(), 1)
+	}
+	if entries[0].Fields["password"] != soba.DefaultRedactMask {
+		t.Fatalf("Unexpected password: '%v' should be '%s'", entries[0].Fields["password"], soba.DefaultRedactMask)
+	}
+}
+
+// Test testing appender.
+func TestTestingAppender(t *testing.T) {
+	logger := sobatest.NewTestingLogger(t, soba.DebugLevel)
+	logger.Info("User created", soba.Int64("id", 7))
+}
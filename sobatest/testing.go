@@ -0,0 +1,54 @@
+package sobatest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/novln/soba"
+	"github.com/novln/soba/encoder/json"
+)
+
+// TestingAppender is an appender that writes every entry with the logging function of a testing.TB, so they are
+// only printed for a failed test or in verbose mode.
+type TestingAppender struct {
+	name string
+	tb   testing.TB
+}
+
+// NewTestingAppender creates a new TestingAppender.
+func NewTestingAppender(name string, tb testing.TB) *TestingAppender {
+	return &TestingAppender{
+		name: name,
+		tb:   tb,
+	}
+}
+
+// Name returns appender name.
+func (appender *TestingAppender) Name() string {
+	return appender.name
+}
+
+// Close recycles underlying resources of appender.
+func (appender *TestingAppender) Close() error {
+	return nil
+}
+
+// Write receives a log entry and writes it with the testing.TB.
+func (appender *TestingAppender) Write(entry *soba.Entry) {
+	encoder := json.NewEncoder()
+	defer encoder.Close()
+
+	buffer := soba.WriteEntry(entry, encoder)
+
+	appender.tb.Log(strings.TrimSuffix(string(buffer), "\n"))
+}
+
+// NewTestingLogger creates a new logger with given level that writes its entries with given testing.TB.
+func NewTestingLogger(tb testing.TB, level soba.Level) soba.Logger {
+	return soba.NewLogger(DefaultLoggerName, level, []soba.Appender{
+		NewTestingAppender(nextAppenderName(), tb),
+	})
+}
+
+// Ensure TestingAppender implements Appender interface at compile time.
+var _ soba.Appender = &TestingAppender{}
@@ -0,0 +1,102 @@
+package soba
+
+import (
+	"log"
+	"strings"
+)
+
+// NewStdLogger creates a new *log.Logger whose output is written by given logger using given level.
+// Its prefix and the header defined by its flags are stripped from the entry message.
+func NewStdLogger(logger Logger, level Level) *log.Logger {
+	writer := &stdLogWriter{
+		logger: logger,
+		level:  level,
+	}
+
+	writer.std = log.New(writer, "", 0)
+
+	return writer.std
+}
+
+// RedirectStdLog redirects the output of the standard logger, from the log package, to given logger using given
+// level. It returns a function that restores the previous output, prefix and flags of the standard logger.
+func RedirectStdLog(logger Logger, level Level) func() {
+	std := log.Default()
+
+	flags := std.Flags()
+	prefix := std.Prefix()
+	output := std.Writer()
+
+	std.SetFlags(0)
+	std.SetPrefix("")
+	std.SetOutput(&stdLogWriter{
+		logger: logger,
+		level:  level,
+		std:    std,
+	})
+
+	return func() {
+		std.SetFlags(flags)
+		std.SetPrefix(prefix)
+		std.SetOutput(output)
+	}
+}
+
+// stdLogWriter is an io.Writer that writes the output of a *log.Logger with a Logger.
+type stdLogWriter struct {
+	logger Logger
+	level  Level
+	std    *log.Logger
+}
+
+// Write parses given output and writes its message with the logger.
+func (writer *stdLogWriter) Write(buffer []byte) (int, error) {
+	logger := writer.logger
+	if !logger.Enabled(writer.level) {
+		return len(buffer), nil
+	}
+
+	message := string(buffer)
+	if writer.std != nil {
+		message = stripStdLogHeader(message, writer.std.Prefix(), writer.std.Flags())
+	}
+
+	logger.write(writer.level, strings.TrimSuffix(message, "\n"), nil)
+
+	return len(buffer), nil
+}
+
+// stripStdLogHeader removes the prefix and the header defined by given flags from given message.
+func stripStdLogHeader(message string, prefix string, flags int) string {
+	if flags&log.Lmsgprefix == 0 {
+		message = strings.TrimPrefix(message, prefix)
+	}
+
+	if flags&log.Ldate != 0 {
+		message = skipStdLogToken(message, " ")
+	}
+
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		message = skipStdLogToken(message, " ")
+	}
+
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		message = skipStdLogToken(message, ": ")
+	}
+
+	if flags&log.Lmsgprefix != 0 {
+		message = strings.TrimPrefix(message, prefix)
+	}
+
+	return message
+}
+
+// skipStdLogToken removes everything from given message until the first occurrence of given separator.
+func skipStdLogToken(message string, separator string) string {
+	idx := strings.Index(message, separator)
+	if idx < 0 {
+		return message
+	}
+
+	return message[idx+len(separator):]
+}
@@ -0,0 +1,119 @@
+package soba_test
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/novln/soba"
+)
+
+// Test standard library logger backed by a Logger.
+func TestStdLog_NewStdLogger(t *testing.T) {
+	appender := NewTestAppender("stdlog-test")
+	logger := soba.NewLogger("net.http", soba.InfoLevel, []soba.Appender{appender})
+
+	scenarios := []struct {
+		prefix string
+		flags  int
+	}{
+		{
+			// Scenario #1
+			prefix: "",
+			flags:  0,
+		},
+		{
+			// Scenario #2
+			prefix: "[http] ",
+			flags:  log.LstdFlags,
+		},
+		{
+			// Scenario #3
+			prefix: "http: ",
+			flags:  log.LstdFlags | log.Lmicroseconds | log.Lshortfile | log.LUTC,
+		},
+		{
+			// Scenario #4
+			prefix: "http: ",
+			flags:  log.Ldate | log.Llongfile | log.Lmsgprefix,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		message := fmt.Sprintf("scenario #%d", (i + 1))
+
+		std := soba.NewStdLogger(logger, soba.WarnLevel)
+		std.SetPrefix(scenario.prefix)
+		std.SetFlags(scenario.flags)
+		std.Printf("TLS handshake error from %s: EOF", "10.0.7.23:52312")
+
+		expected := fmt.Sprint(
+			`{"logger":"net.http","level":"warning","message":"TLS handshake error from 10.0.7.23:52312: EOF"}`,
+			"\n",
+		)
+
+		if appender.Size() != 1 {
+			t.Fatalf("Unexpected number of entries for %s: %d should be %d", message, appender.Size(), 1)
+		}
+		if appender.Log(0) != expected {
+			t.Fatalf("Unexpected log message for %s: '%s' should be '%s'", message, appender.Log(0), expected)
+		}
+
+		appender.Clear()
+	}
+
+	std := soba.NewStdLogger(logger, soba.DebugLevel)
+	std.Print("Connection accepted")
+
+	if appender.Size() != 0 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Size(), 0)
+	}
+}
+
+// Test redirection of the standard library logger.
+func TestStdLog_RedirectStdLog(t *testing.T) {
+	appender := NewTestAppender("stdlog-test")
+	logger := soba.NewLogger("stdlog", soba.DebugLevel, []soba.Appender{appender})
+
+	buffer := &bytes.Buffer{}
+	output := log.Writer()
+	log.SetOutput(buffer)
+	defer log.SetOutput(output)
+
+	log.SetPrefix("app: ")
+	defer log.SetPrefix("")
+
+	restore := soba.RedirectStdLog(logger, soba.InfoLevel)
+
+	log.Printf("Server started on port %d", 8080)
+	log.SetFlags(log.Lshortfile)
+	log.Println("Server stopped")
+
+	restore()
+
+	log.Print("Bye")
+
+	expected1 := `{"logger":"stdlog","level":"info","message":"Server started on port 8080"}` + "\n"
+	expected2 := `{"logger":"stdlog","level":"info","message":"Server stopped"}` + "\n"
+
+	if appender.Size() != 2 {
+		t.Fatalf("Unexpected number of entries: %d should be %d", appender.Size(), 2)
+	}
+	if appender.Log(0) != expected1 {
+		t.Fatalf("Unexpected log message #1: '%s' should be '%s'", appender.Log(0), expected1)
+	}
+	if appender.Log(1) != expected2 {
+		t.Fatalf("Unexpected log message #2: '%s' should be '%s'", appender.Log(1), expected2)
+	}
+
+	if log.Prefix() != "app: " {
+		t.Fatalf("Unexpected prefix: '%s' should be '%s'", log.Prefix(), "app: ")
+	}
+	if log.Flags() != log.LstdFlags {
+		t.Fatalf("Unexpected flags: %d should be %d", log.Flags(), log.LstdFlags)
+	}
+	if buffer.String() == "" {
+		t.Fatal("Standard logger output should be restored")
+	}
+}
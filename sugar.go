@@ -0,0 +1,114 @@
+package soba
+
+import (
+	"fmt"
+)
+
+// BadKey is the key used for a value without a valid string key in a list of loosely-typed key/value pairs.
+const BadKey = "!BADKEY"
+
+// A SugaredLogger wraps a Logger to provide a printf-style API and loosely-typed key/value pairs.
+// It's slower than Logger, and should be used by packages where typed fields are not practical.
+// All methods are safe for concurrent use.
+type SugaredLogger struct {
+	logger Logger
+}
+
+// Sugar returns a SugaredLogger that wraps this logger.
+func (logger Logger) Sugar() SugaredLogger {
+	return SugaredLogger{
+		logger: logger,
+	}
+}
+
+// Desugar returns the underlying Logger.
+func (sugar SugaredLogger) Desugar() Logger {
+	return sugar.logger
+}
+
+// With appends given loosely-typed key/value pairs to it.
+func (sugar SugaredLogger) With(keysAndValues ...interface{}) SugaredLogger {
+	return SugaredLogger{
+		logger: sugar.logger.With(getSugaredFields(keysAndValues)...),
+	}
+}
+
+// Debugf formats a message with fmt.Sprintf and logs it at DebugLevel.
+func (sugar SugaredLogger) Debugf(template string, args ...interface{}) {
+	sugar.logf(DebugLevel, template, args)
+}
+
+// Infof formats a message with fmt.Sprintf and logs it at InfoLevel.
+func (sugar SugaredLogger) Infof(template string, args ...interface{}) {
+	sugar.logf(InfoLevel, template, args)
+}
+
+// Warnf formats a message with fmt.Sprintf and logs it at WarnLevel.
+func (sugar SugaredLogger) Warnf(template string, args ...interface{}) {
+	sugar.logf(WarnLevel, template, args)
+}
+
+// Errorf formats a message with fmt.Sprintf and logs it at ErrorLevel.
+func (sugar SugaredLogger) Errorf(template string, args ...interface{}) {
+	sugar.logf(ErrorLevel, template, args)
+}
+
+// Debugw logs a message with loosely-typed key/value pairs at DebugLevel.
+func (sugar SugaredLogger) Debugw(message string, keysAndValues ...interface{}) {
+	sugar.logw(DebugLevel, message, keysAndValues)
+}
+
+// Infow logs a message with loosely-typed key/value pairs at InfoLevel.
+func (sugar SugaredLogger) Infow(message string, keysAndValues ...interface{}) {
+	sugar.logw(InfoLevel, message, keysAndValues)
+}
+
+// Warnw logs a message with loosely-typed key/value pairs at WarnLevel.
+func (sugar SugaredLogger) Warnw(message string, keysAndValues ...interface{}) {
+	sugar.logw(WarnLevel, message, keysAndValues)
+}
+
+// Errorw logs a message with loosely-typed key/value pairs at ErrorLevel.
+func (sugar SugaredLogger) Errorw(message string, keysAndValues ...interface{}) {
+	sugar.logw(ErrorLevel, message, keysAndValues)
+}
+
+func (sugar SugaredLogger) logf(level Level, template string, args []interface{}) {
+	if !sugar.logger.Enabled(level) {
+		return
+	}
+	sugar.logger.write(level, fmt.Sprintf(template, args...), nil)
+}
+
+func (sugar SugaredLogger) logw(level Level, message string, keysAndValues []interface{}) {
+	if !sugar.logger.Enabled(level) {
+		return
+	}
+	sugar.logger.write(level, message, getSugaredFields(keysAndValues))
+}
+
+// getSugaredFields converts given loosely-typed key/value pairs to fields.
+// A Field is used as it is, and a value is converted with Any. A value without a string key, or a key without a
+// value, is written with BadKey.
+func getSugaredFields(keysAndValues []interface{}) []Field {
+	fields := make([]Field, 0, len(keysAndValues)/2+1)
+
+	for i := 0; i < len(keysAndValues); i++ {
+		field, ok := keysAndValues[i].(Field)
+		if ok {
+			fields = append(fields, field)
+			continue
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok || i+1 == len(keysAndValues) {
+			fields = append(fields, Any(BadKey, keysAndValues[i]))
+			continue
+		}
+
+		fields = append(fields, Any(key, keysAndValues[i+1]))
+		i++
+	}
+
+	return fields
+}
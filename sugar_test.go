@@ -0,0 +1,98 @@
+package soba_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/novln/soba"
+)
+
+// Test sugared logger with printf-style messages.
+func TestSugaredLogger_Printf(t *testing.T) {
+	appender := NewTestAppender("foobar")
+	defer CloseAppender(t, appender)
+
+	logger := soba.NewLogger("foobar", soba.InfoLevel, []soba.Appender{appender})
+	sugar := logger.Sugar().With("service", "api")
+
+	sugar.Debugf("Cache updated with %d keys", 42)
+	sugar.Infof("User %s created", "john")
+	sugar.Warnf("Request took %s", 3*time.Second)
+	sugar.Errorf("Cannot connect to %s: %v", "10.0.7.23", errors.New("connection refused"))
+
+	expected := []string{
+		fmt.Sprint(
+			`{"logger":"foobar","level":"info","message":"User john created","service":"api"}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"foobar","level":"warning","message":"Request took 3s","service":"api"}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"foobar","level":"error","message":"Cannot connect to 10.0.7.23: connection refused",`,
+			`"service":"api"}`,
+			"\n",
+		),
+	}
+
+	if appender.Size() != len(expected) {
+		t.Fatalf("Unexpected number of entries for appender: %d should be %d", appender.Size(), len(expected))
+	}
+
+	for i := range expected {
+		if appender.Log(i) != expected[i] {
+			t.Fatalf("Unexpected log message #%d: '%s' should be '%s'", i+1, appender.Log(i), expected[i])
+		}
+	}
+
+	if sugar.Desugar().Name() != "foobar" {
+		t.Fatalf("Unexpected logger name: '%s' should be '%s'", sugar.Desugar().Name(), "foobar")
+	}
+}
+
+// Test sugared logger with loosely-typed key/value pairs.
+func TestSugaredLogger_KeysAndValues(t *testing.T) {
+	appender := NewTestAppender("foobar")
+	defer CloseAppender(t, appender)
+
+	logger := soba.NewLogger("foobar", soba.DebugLevel, []soba.Appender{appender})
+	sugar := logger.Sugar()
+
+	sugar.Debugw("Cache updated", "size", 42, "keys", []string{"foo", "bar"})
+	sugar.Infow("User created", "id", int64(7), soba.Bool("admin", true), "elapsed", 3*time.Millisecond)
+	sugar.Warnw("Invalid pairs", 42, "foo", "bar", "dangling")
+	sugar.Errorw("Request failed", "error", errors.New("timeout"))
+
+	expected := []string{
+		fmt.Sprint(
+			`{"logger":"foobar","level":"debug","message":"Cache updated","size":42,"keys":["foo","bar"]}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"foobar","level":"info","message":"User created","id":7,"admin":true,"elapsed":"3ms"}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"foobar","level":"warning","message":"Invalid pairs","!BADKEY":"dangling","foo":"bar"}`,
+			"\n",
+		),
+		fmt.Sprint(
+			`{"logger":"foobar","level":"error","message":"Request failed",`,
+			`"error":{"message":"timeout","type":"*errors.errorString"}}`,
+			"\n",
+		),
+	}
+
+	if appender.Size() != len(expected) {
+		t.Fatalf("Unexpected number of entries for appender: %d should be %d", appender.Size(), len(expected))
+	}
+
+	for i := range expected {
+		if appender.Log(i) != expected[i] {
+			t.Fatalf("Unexpected log message #%d: '%s' should be '%s'", i+1, appender.Log(i), expected[i])
+		}
+	}
+}
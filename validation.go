@@ -0,0 +1,291 @@
+package soba
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A ConfigIssue describes a problem of a configuration, such as an invalid value, or a dead configuration like an
+// appender referenced by no logger.
+type ConfigIssue struct {
+	// Path defines the key path of the problem, such as "loggers.app.requests.appenders[0]".
+	Path string
+	// File defines the configuration file that declares the key, or the environment variable that overrides it,
+	// such as "$SOBA_ROOT_LEVEL", if known.
+	File string
+	// Line defines the line of the key in its configuration file, if known. It's only available for YAML files.
+	Line int
+	// Message describes the problem.
+	Message string
+}
+
+// Error returns the issue with its location, such as:
+//
+//	soba.yml:12: loggers.app.requests.appenders[0]: unknown appender "reqests" (did you mean "requests"?)
+func (issue *ConfigIssue) Error() string {
+	buffer := strings.Builder{}
+	if issue.File != "" {
+		buffer.WriteString(issue.File)
+		if issue.Line > 0 {
+			buffer.WriteString(":")
+			buffer.WriteString(strconv.Itoa(issue.Line))
+		}
+		buffer.WriteString(": ")
+	}
+	if issue.Path != "" {
+		buffer.WriteString(issue.Path)
+		buffer.WriteString(": ")
+	}
+	buffer.WriteString(issue.Message)
+	return buffer.String()
+}
+
+// position defines where a key is declared in a configuration file.
+type position struct {
+	file string
+	line int
+}
+
+// configValidator collects every issue of a configuration, rather than stopping at the first one.
+type configValidator struct {
+	positions map[string]position
+	errors    []*ConfigIssue
+	warnings  []*ConfigIssue
+}
+
+// newConfigValidator creates a new configValidator, which locates the issues of given configuration.
+// The configuration could be nil.
+func newConfigValidator(conf *Config) *configValidator {
+	validator := &configValidator{}
+	if conf != nil {
+		validator.positions = conf.positions
+	}
+	return validator
+}
+
+// errorf adds an error for given key path.
+func (validator *configValidator) errorf(path string, format string, args ...interface{}) {
+	validator.errors = append(validator.errors, validator.issue(path, fmt.Sprintf(format, args...)))
+}
+
+// warnf adds a warning for given key path.
+func (validator *configValidator) warnf(path string, format string, args ...interface{}) {
+	validator.warnings = append(validator.warnings, validator.issue(path, fmt.Sprintf(format, args...)))
+}
+
+// issue creates an issue for given key path, located with the nearest declared key.
+func (validator *configValidator) issue(path string, message string) *ConfigIssue {
+	issue := &ConfigIssue{
+		Path:    path,
+		Message: message,
+	}
+
+	for cursor := path; ; cursor = parentPath(cursor) {
+		position, ok := validator.positions[cursor]
+		if ok {
+			issue.File = position.file
+			issue.Line = position.line
+			break
+		}
+		if cursor == "" {
+			break
+		}
+	}
+
+	return issue
+}
+
+// err returns the collected errors as a MultiError, or nil if there is none.
+func (validator *configValidator) err() error {
+	if len(validator.errors) == 0 {
+		return nil
+	}
+
+	sortConfigIssues(validator.errors)
+
+	list := make(MultiError, len(validator.errors))
+	for i := range validator.errors {
+		list[i] = validator.errors[i]
+	}
+
+	return list
+}
+
+// sortConfigIssues sorts given issues by file and line, and then by key path, since maps are iterated in
+// random order.
+func sortConfigIssues(issues []*ConfigIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Path < issues[j].Path
+	})
+}
+
+// parentPath returns the parent of given key path, such as "root" for "root.appenders[0]".
+func parentPath(path string) string {
+	i := strings.LastIndexAny(path, ".[")
+	if i < 0 {
+		return ""
+	}
+	return path[:i]
+}
+
+// joinPath returns the key path of given key in given parent path, such as "root.level".
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// indexPath returns the key path of given index in given parent path, such as "root.appenders[0]".
+func indexPath(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+// suggest returns a suggestion for given invalid value, using the nearest candidate, such as:
+//
+//	(did you mean "requests"?)
+//
+// If no candidate is near enough, an empty string is returned.
+func suggest(value string, candidates []string) string {
+	best := ""
+	distance := -1
+
+	for _, candidate := range candidates {
+		if candidate == "" || candidate == value {
+			continue
+		}
+		current := levenshtein(strings.ToLower(value), strings.ToLower(candidate))
+		if distance < 0 || current < distance || (current == distance && candidate < best) {
+			best = candidate
+			distance = current
+		}
+	}
+
+	if distance < 0 || (distance > 2 && distance > len(value)/3) {
+		return ""
+	}
+
+	return fmt.Sprintf(` (did you mean "%s"?)`, best)
+}
+
+// levenshtein returns the edit distance between given strings.
+func levenshtein(a string, b string) int {
+	x := []rune(a)
+	y := []rune(b)
+
+	previous := make([]int, len(y)+1)
+	current := make([]int, len(y)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		current[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(y)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// findUnknownKeys returns an issue for every key of given value that doesn't match a field of given type, using
+// its yaml tags. A free-form value, such as the static fields or the appender options, is not verified.
+func findUnknownKeys(value interface{}, kind reflect.Type, path string) []*ConfigIssue {
+	issues := []*ConfigIssue{}
+
+	if kind == reflect.TypeOf(ConfigOptions{}) {
+		return issues
+	}
+
+	switch kind.Kind() {
+	case reflect.Ptr:
+		return findUnknownKeys(value, kind.Elem(), path)
+
+	case reflect.Struct:
+		tree, ok := value.(document)
+		if !ok {
+			return issues
+		}
+
+		fields := map[string]reflect.Type{}
+		names := []string{}
+		for i := 0; i < kind.NumField(); i++ {
+			field := kind.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if field.PkgPath != "" || name == "" || name == "-" {
+				continue
+			}
+			fields[name] = field.Type
+			names = append(names, name)
+		}
+
+		for _, key := range sortedDocumentKeys(tree) {
+			field, ok := fields[key]
+			if !ok {
+				issues = append(issues, &ConfigIssue{
+					Path:    joinPath(path, key),
+					Message: fmt.Sprintf(`unknown key "%s"%s`, key, suggest(key, names)),
+				})
+				continue
+			}
+			issues = append(issues, findUnknownKeys(tree[key], field, joinPath(path, key))...)
+		}
+
+	case reflect.Map:
+		tree, ok := value.(document)
+		if !ok {
+			return issues
+		}
+
+		for _, key := range sortedDocumentKeys(tree) {
+			issues = append(issues, findUnknownKeys(tree[key], kind.Elem(), joinPath(path, key))...)
+		}
+
+	case reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return issues
+		}
+
+		for i := range list {
+			issues = append(issues, findUnknownKeys(list[i], kind.Elem(), indexPath(path, i))...)
+		}
+	}
+
+	return issues
+}
+
+// sortedDocumentKeys returns the keys of given document, in order.
+func sortedDocumentKeys(tree document) []string {
+	keys := make([]string, 0, len(tree))
+	for key := range tree {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
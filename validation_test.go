@@ -0,0 +1,162 @@
+package soba_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/novln/soba"
+)
+
+// Test that every problem of a configuration file is reported, with its key path and line number.
+func TestValidation_ParseConfig(t *testing.T) {
+	_, err := soba.ParseConfig("testdata/validation.yaml")
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	list, ok := errors.Cause(err).(soba.MultiError)
+	if !ok {
+		t.Fatalf("Unexpected error type: %T", errors.Cause(err))
+	}
+
+	expected := []string{
+		`testdata/validation.yaml:8: appenders.events.type: unknown appender type "consol" (did you mean "console"?)`,
+		`testdata/validation.yaml:11: root.level: unknown level "wran" (did you mean "warn"?)`,
+		`testdata/validation.yaml:20: loggers.app.requests.appenders[0]: unknown appender "reqests" ` +
+			`(did you mean "requests"?)`,
+	}
+
+	if len(list) != len(expected) {
+		t.Fatalf("Unexpected number of errors: %d should be %d (%s)", len(list), len(expected), err)
+	}
+
+	for i := range expected {
+		issue, ok := list[i].(*soba.ConfigIssue)
+		if !ok {
+			t.Fatalf("Unexpected error type: %T", list[i])
+		}
+		if issue.Error() != expected[i] {
+			t.Fatalf("Unexpected error #%d: '%s' should be '%s'", i+1, issue, expected[i])
+		}
+	}
+
+	if list[2].(*soba.ConfigIssue).Path != "loggers.app.requests.appenders[0]" {
+		t.Fatalf("Unexpected path: %s", list[2].(*soba.ConfigIssue).Path)
+	}
+	if list[2].(*soba.ConfigIssue).Line != 20 {
+		t.Fatalf("Unexpected line: %d should be %d", list[2].(*soba.ConfigIssue).Line, 20)
+	}
+}
+
+// Test that an issue is located with its environment variable if its value is overridden.
+func TestValidation_ParseConfigWithEnv(t *testing.T) {
+	t.Setenv("SOBA_ROOT_LEVEL", "trace")
+
+	_, err := soba.ParseConfig("testdata/simple.yaml")
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+	if !strings.HasSuffix(err.Error(), `: $SOBA_ROOT_LEVEL: root.level: unknown level "trace"`) {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+// Test warnings and strict mode of a configuration file.
+func TestValidation_Warnings(t *testing.T) {
+	conf, err := soba.ParseConfig("testdata/warnings.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	expected := []string{
+		`testdata/warnings.yaml:7: appenders.audit: appender "audit" is not used by any logger`,
+		`testdata/warnings.yaml:21: loggers.app.requests.aditive: unknown key "aditive" (did you mean "additive"?)`,
+		`testdata/warnings.yaml:24: loggers.app.legacy.appenders: appenders are ignored since logger is disabled`,
+	}
+
+	warnings := conf.Warnings()
+	if len(warnings) != len(expected) {
+		t.Fatalf("Unexpected number of warnings: %d should be %d (%v)", len(warnings), len(expected), warnings)
+	}
+
+	for i := range expected {
+		if warnings[i].Error() != expected[i] {
+			t.Fatalf("Unexpected warning #%d: '%s' should be '%s'", i+1, warnings[i], expected[i])
+		}
+	}
+
+	_, err = soba.ParseConfigStrict("testdata/warnings.yaml")
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	list, ok := errors.Cause(err).(soba.MultiError)
+	if !ok || len(list) != 1 || list[0].Error() != expected[1] {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	_, err = soba.ParseConfigStrict("testdata/simple.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+}
+
+// Test that every problem of a configuration is reported with its key path.
+func TestValidation_ValidateConfig(t *testing.T) {
+	conf := soba.NewDefaultConfig()
+	conf.Root.Level = "debg"
+	conf.Appenders["requests"] = soba.ConfigAppender{
+		Type:     soba.FileAppenderType,
+		MaxBytes: -1,
+		Encoder:  "jsn",
+	}
+	conf.Keys.Policy = "snakecase"
+
+	err := soba.ValidateConfig(conf)
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+
+	expected := []string{
+		`appenders.requests.encoder: unknown encoder "jsn" (did you mean "json"?)`,
+		`appenders.requests.max_bytes: max bytes should be positive: -1`,
+		`appenders.requests.path: path is required`,
+		`keys.policy: unknown key policy "snakecase" (did you mean "snake_case"?)`,
+		`root.level: unknown level "debg" (did you mean "debug"?)`,
+	}
+
+	if err.Error() != strings.Join(expected, "; ") {
+		t.Fatalf("Unexpected error: '%s' should be '%s'", err, strings.Join(expected, "; "))
+	}
+
+	warnings := conf.Warnings()
+	if len(warnings) != 1 || warnings[0].Error() != `appenders.requests: appender "requests" is not used by any logger` {
+		t.Fatalf("Unexpected warnings: %v", warnings)
+	}
+}
+
+// Test that only the root logger requires a level, since a logger inherits the level of its ancestors.
+func TestValidation_LoggerLevel(t *testing.T) {
+	conf := soba.NewDefaultConfig()
+	conf.Loggers = map[string]soba.ConfigLogger{
+		"app.requests": {},
+	}
+
+	err := soba.ValidateConfig(conf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %+v", err)
+	}
+
+	conf = soba.NewDefaultConfig()
+	conf.Root.Level = ""
+
+	err = soba.ValidateConfig(conf)
+	if err == nil {
+		t.Fatal("An error was expected")
+	}
+	if err.Error() != "root.level: level is required" {
+		t.Fatalf("Unexpected error: '%s' should be '%s'", err, "root.level: level is required")
+	}
+}